@@ -0,0 +1,108 @@
+package stegano
+
+import (
+	"image"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// adaptiveOrder computes the cost map of the channels and returns the samples ordered from cheapest to most expensive.
+func adaptiveOrder(RGBchannels []u.RgbChannel, width, height int, bitDepth uint8) ([]int, error) {
+	costs, err := u.CostMap(RGBchannels, width, height, bitDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.AdaptiveOrder(costs), nil
+}
+
+// EmbedAdaptive embeds data into the last bitDepth+1 bits of the channels with the lowest embedding cost
+// instead of filling the image from the top left. The cost of a channel is derived from the local variance
+// of the bits above bitDepth, so changes end up in textured areas where they are harder to detect.
+// The capacity is the same as with EmbedDataIntoImage.
+//
+// Parameters:
+// - coverImage: The image to embed data into.
+// - data: The data to embed.
+// - bitDepth: The bit depth used for embedding (valid range: 0-7).
+func (m *EmbedHandler) EmbedAdaptive(coverImage image.Image, data []byte, bitDepth uint8) (image.Image, error) {
+	if coverImage == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if bitDepth > 7 {
+		return nil, ErrDepthOutOfRange
+	}
+
+	if len(data) == 0 {
+		return nil, ErrInvalidData
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	if (len(data)*8)+32 > len(RGBchannels)*3*(int(bitDepth)+1) {
+		return nil, ErrDataTooLarge
+	}
+
+	order, err := adaptiveOrder(RGBchannels, width, height, bitDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddedRGBChannels, err := u.EmbedIntoRGBchannelsWithOrder(RGBchannels, data, bitDepth, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.SaveImage(embeddedRGBChannels, height, width)
+}
+
+// ExtractAdaptive extracts data embedded with EmbedAdaptive, recomputing the embedding order from the image.
+//
+// Parameters:
+// - coverImage: The image containing the embedded data.
+// - bitDepth: The bit depth used during embedding.
+func (m *ExtractHandler) ExtractAdaptive(coverImage image.Image, bitDepth uint8) ([]byte, error) {
+	if coverImage == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if bitDepth > 7 {
+		return nil, ErrDepthOutOfRange
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	order, err := adaptiveOrder(RGBchannels, width, height, bitDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.ExtractDataFromRGBchannelsWithOrder(RGBchannels, bitDepth, order)
+}
@@ -0,0 +1,25 @@
+package stegano
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAdaptive_RoundTrip(t *testing.T) {
+	cover := createGradientImage(64, 64)
+	data := []byte("cost map driven embedding")
+
+	stego, err := NewEmbedHandler().EmbedAdaptive(cover, data, LSB)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	extracted, err := NewExtractHandler().ExtractAdaptive(stego, LSB)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if !bytes.Equal(extracted, data) {
+		t.Fatalf("expected %q, got %q", data, extracted)
+	}
+}
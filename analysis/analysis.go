@@ -0,0 +1,95 @@
+// Package analysis implements classic steganalysis attacks against LSB embedding, so the
+// detectability of an embedding mode and bit depth can be measured before an image is shared.
+// Every attack returns an estimate of the fraction of samples carrying a message, per channel.
+package analysis
+
+import (
+	"errors"
+	"image"
+	"runtime"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+var ErrInvalidImage = errors.New("image is nil or too small to analyse")
+
+// Estimate holds the estimated embedding rate of each color channel, between 0 (clean) and 1 (every sample carries a bit).
+type Estimate struct {
+	R, G, B float64
+}
+
+// Max returns the highest rate of the three channels.
+func (e Estimate) Max() float64 {
+	return max(e.R, e.G, e.B)
+}
+
+// Report combines the results of all attacks.
+type Report struct {
+	ChiSquare Estimate
+	RS        Estimate
+	SPA       Estimate
+}
+
+// plane is a single color channel of an image in row-major order.
+type plane struct {
+	values        []int
+	width, height int
+}
+
+// planes splits img into its red, green and blue channels.
+func planes(img image.Image) ([3]plane, error) {
+	var p [3]plane
+	if img == nil {
+		return p, ErrInvalidImage
+	}
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width < 4 || height < 1 {
+		return p, ErrInvalidImage
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(img, runtime.NumCPU())
+	for c := range p {
+		p[c] = plane{values: make([]int, len(RGBchannels)), width: width, height: height}
+	}
+
+	for i, px := range RGBchannels {
+		p[0].values[i] = int(px.R)
+		p[1].values[i] = int(px.G)
+		p[2].values[i] = int(px.B)
+	}
+
+	return p, nil
+}
+
+// perChannel runs fn on each channel of img.
+func perChannel(img image.Image, fn func(plane) float64) (Estimate, error) {
+	p, err := planes(img)
+	if err != nil {
+		return Estimate{}, err
+	}
+
+	return Estimate{R: fn(p[0]), G: fn(p[1]), B: fn(p[2])}, nil
+}
+
+func clampRate(rate float64) float64 {
+	return min(max(rate, 0), 1)
+}
+
+// Analyze runs the chi-square attack, RS analysis and sample pair analysis on img.
+func Analyze(img image.Image) (Report, error) {
+	p, err := planes(img)
+	if err != nil {
+		return Report{}, err
+	}
+
+	run := func(fn func(plane) float64) Estimate {
+		return Estimate{R: fn(p[0]), G: fn(p[1]), B: fn(p[2])}
+	}
+
+	return Report{
+		ChiSquare: run(chiSquareRate),
+		RS:        run(rsRate),
+		SPA:       run(spaRate),
+	}, nil
+}
@@ -0,0 +1,131 @@
+package analysis
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// createCoverImage builds a smooth image with sensor-like noise. With comb set the values are quantized
+// to multiples of three, giving the uneven histogram of a processed photo that the chi-square attack needs.
+func createCoverImage(width, height int, comb bool) *image.RGBA {
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			base := 128 + 60*math.Sin(float64(x)/23)*math.Cos(float64(y)/31)
+			sample := func(offset float64) uint8 {
+				v := min(max(base+offset+rng.NormFloat64()*2, 0), 255)
+				if comb {
+					v = min(3*math.Round(v/3), 255)
+				}
+				return uint8(v)
+			}
+			img.SetRGBA(x, y, color.RGBA{R: sample(0), G: sample(20), B: sample(-20), A: 255})
+		}
+	}
+
+	return img
+}
+
+// embedLSB replaces the LSBs of a fraction of the pixels with random bits, either the first pixels or randomly chosen ones.
+func embedLSB(cover *image.RGBA, rate float64, sequential bool) *image.RGBA {
+	rng := rand.New(rand.NewSource(2))
+	stego := image.NewRGBA(cover.Bounds())
+	copy(stego.Pix, cover.Pix)
+
+	n := len(stego.Pix) / 4
+	for i := 0; i < n; i++ {
+		if sequential && float64(i) >= rate*float64(n) {
+			break
+		}
+
+		if !sequential && rng.Float64() >= rate {
+			continue
+		}
+
+		for c := 0; c < 3; c++ {
+			stego.Pix[i*4+c] = stego.Pix[i*4+c]&^1 | uint8(rng.Intn(2))
+		}
+	}
+
+	return stego
+}
+
+func checkEstimate(t *testing.T, name string, got Estimate, want, tolerance float64) {
+	t.Helper()
+	for c, rate := range []float64{got.R, got.G, got.B} {
+		if math.Abs(rate-want) > tolerance {
+			t.Errorf("%s: channel %d estimated %.3f, expected %.2f", name, c, rate, want)
+		}
+	}
+}
+
+func TestChiSquare(t *testing.T) {
+	cover := createCoverImage(256, 256, true)
+
+	for _, rate := range []float64{0, 0.3, 0.6} {
+		est, err := ChiSquare(embedLSB(cover, rate, true))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkEstimate(t, "chi-square", est, rate, 0.05)
+	}
+}
+
+func TestRS(t *testing.T) {
+	cover := createCoverImage(256, 256, false)
+
+	for _, rate := range []float64{0, 0.3, 0.6} {
+		est, err := RS(embedLSB(cover, rate, false))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkEstimate(t, "RS", est, rate, 0.1)
+	}
+}
+
+func TestSPA(t *testing.T) {
+	cover := createCoverImage(256, 256, false)
+
+	for _, rate := range []float64{0, 0.3, 0.6} {
+		est, err := SPA(embedLSB(cover, rate, false))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkEstimate(t, "SPA", est, rate, 0.1)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	cover := createCoverImage(128, 128, false)
+
+	clean, err := Analyze(cover)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stego, err := Analyze(embedLSB(cover, 1, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stego.SPA.Max() <= clean.SPA.Max() || stego.RS.Max() <= clean.RS.Max() {
+		t.Fatalf("expected fully embedded image to score higher: clean %+v, stego %+v", clean, stego)
+	}
+}
+
+func TestInvalidImage(t *testing.T) {
+	if _, err := Analyze(nil); err != ErrInvalidImage {
+		t.Fatalf("expected %v, got %v", ErrInvalidImage, err)
+	}
+
+	if _, err := RS(image.NewRGBA(image.Rect(0, 0, 2, 2))); err != ErrInvalidImage {
+		t.Fatalf("expected %v, got %v", ErrInvalidImage, err)
+	}
+}
@@ -0,0 +1,113 @@
+package analysis
+
+import (
+	"image"
+	"math"
+)
+
+// chiSquareSteps is the number of growing prefixes of the channel the attack is evaluated on.
+const chiSquareSteps = 100
+
+// ChiSquare runs the chi-square attack by Westfeld and Pfitzmann. LSB replacement equalises the
+// frequencies of each pair of values 2k and 2k+1, so the probability that the histogram of a prefix of
+// the channel comes from a stego image stays close to 1 for as long as the prefix is fully embedded.
+// The estimate is the longest prefix, as a fraction of the channel, whose probability is above 0.5.
+// The attack assumes sequential embedding as done by Encode and detects randomly spread payloads poorly.
+func ChiSquare(img image.Image) (Estimate, error) {
+	return perChannel(img, chiSquareRate)
+}
+
+func chiSquareRate(p plane) float64 {
+	n := len(p.values)
+	var hist [256]int
+	embedded := 0
+	pos := 0
+
+	for step := 1; step <= chiSquareSteps; step++ {
+		end := step * n / chiSquareSteps
+		for ; pos < end; pos++ {
+			hist[p.values[pos]&0xFF]++
+		}
+
+		if chiSquareProbability(&hist) > 0.5 {
+			embedded = end
+		}
+	}
+
+	return float64(embedded) / float64(n)
+}
+
+// chiSquareProbability returns the probability that the pairs of values in hist have equal frequencies.
+func chiSquareProbability(hist *[256]int) float64 {
+	chi := 0.0
+	categories := 0
+	for k := 0; k < 128; k++ {
+		expected := float64(hist[2*k]+hist[2*k+1]) / 2
+		// categories with too few samples make the statistic unreliable
+		if expected <= 4 {
+			continue
+		}
+
+		d := float64(hist[2*k]) - expected
+		chi += d * d / expected
+		categories++
+	}
+
+	if categories < 2 {
+		return 0
+	}
+
+	return upperIncompleteGamma(float64(categories-1)/2, chi/2)
+}
+
+// upperIncompleteGamma computes the regularized upper incomplete gamma function Q(a, x),
+// which is the survival function of the chi-square distribution with 2a degrees of freedom at 2x.
+func upperIncompleteGamma(a, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+
+	lgamma, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lgamma)
+
+	if x < a+1 {
+		// series expansion of the lower function
+		sum, term := 1/a, 1/a
+		for n := 1; n < 1000; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if term < sum*1e-15 {
+				break
+			}
+		}
+
+		return max(1-sum*prefix, 0)
+	}
+
+	// continued fraction, evaluated with the modified Lentz method
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < 1000; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+
+	return prefix * h
+}
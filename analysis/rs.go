@@ -0,0 +1,134 @@
+package analysis
+
+import (
+	"image"
+	"math"
+)
+
+// rsMask is the flipping mask applied to each group of four horizontally adjacent samples.
+var rsMask = [4]int{0, 1, 1, 0}
+
+// RS runs RS analysis by Fridrich, Goljan and Du. Samples are split into groups of four whose smoothness
+// is compared before and after flipping the LSBs (F1) or the shifted LSBs (F-1) of the masked samples.
+// In a clean image both flips make about as many groups regular as singular, while LSB embedding
+// moves the F1 statistics towards each other and the F-1 statistics apart. The estimate works for
+// payloads spread anywhere in the channel but becomes unstable close to full embedding.
+func RS(img image.Image) (Estimate, error) {
+	return perChannel(img, rsRate)
+}
+
+// flip1 swaps 2k and 2k+1.
+func flip1(v int) int {
+	return v ^ 1
+}
+
+// flipNeg1 swaps 2k-1 and 2k.
+func flipNeg1(v int) int {
+	return ((v + 1) ^ 1) - 1
+}
+
+func smoothness(g *[4]int) int {
+	s := 0
+	for i := 0; i < 3; i++ {
+		s += abs(g[i+1] - g[i])
+	}
+
+	return s
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+// rsCounts returns the relative number of regular and singular groups under the mask with positive and negative flipping.
+// With invert set the LSBs of every sample are flipped first.
+func rsCounts(p plane, invert bool) (rm, sm, rn, sn float64) {
+	var group, flipped [4]int
+	total := 0
+
+	for y := 0; y < p.height; y++ {
+		row := p.values[y*p.width : (y+1)*p.width]
+		for x := 0; x+4 <= p.width; x += 4 {
+			for i := range group {
+				group[i] = row[x+i]
+				if invert {
+					group[i] = flip1(group[i])
+				}
+			}
+
+			before := smoothness(&group)
+			total++
+
+			for i := range flipped {
+				flipped[i] = group[i]
+				if rsMask[i] == 1 {
+					flipped[i] = flip1(group[i])
+				}
+			}
+			if after := smoothness(&flipped); after > before {
+				rm++
+			} else if after < before {
+				sm++
+			}
+
+			for i := range flipped {
+				flipped[i] = group[i]
+				if rsMask[i] == 1 {
+					flipped[i] = flipNeg1(group[i])
+				}
+			}
+			if after := smoothness(&flipped); after > before {
+				rn++
+			} else if after < before {
+				sn++
+			}
+		}
+	}
+
+	t := float64(total)
+	return rm / t, sm / t, rn / t, sn / t
+}
+
+func rsRate(p plane) float64 {
+	rm, sm, rn, sn := rsCounts(p, false)
+	rmi, smi, rni, sni := rsCounts(p, true)
+
+	d0 := rm - sm
+	d1 := rmi - smi
+	dn0 := rn - sn
+	dn1 := rni - sni
+
+	a := 2 * (d1 + d0)
+	b := dn0 - dn1 - d1 - 3*d0
+	c := d0 - dn0
+
+	var x float64
+	if math.Abs(a) < 1e-12 {
+		if math.Abs(b) < 1e-12 {
+			return 0
+		}
+		x = -c / b
+	} else {
+		disc := b*b - 4*a*c
+		if disc < 0 {
+			disc = 0
+		}
+
+		x1 := (-b + math.Sqrt(disc)) / (2 * a)
+		x2 := (-b - math.Sqrt(disc)) / (2 * a)
+		x = x1
+		if math.Abs(x2) < math.Abs(x1) {
+			x = x2
+		}
+	}
+
+	if x == 0.5 {
+		return 1
+	}
+
+	return clampRate(x / (x - 0.5))
+}
@@ -0,0 +1,59 @@
+package analysis
+
+import (
+	"image"
+	"math"
+)
+
+// SPA runs sample pair analysis by Dumitrescu, Wu and Wang on horizontally adjacent samples.
+// LSB embedding changes the balance between the pairs whose ordering agrees with the parity of the
+// second sample and those whose ordering disagrees with it; the estimate is the smaller root of the
+// quadratic relating that imbalance to the embedding rate.
+func SPA(img image.Image) (Estimate, error) {
+	return perChannel(img, spaRate)
+}
+
+func spaRate(p plane) float64 {
+	var x, y, k, pairs float64
+
+	for row := 0; row < p.height; row++ {
+		values := p.values[row*p.width : (row+1)*p.width]
+		for i := 0; i+1 < len(values); i++ {
+			u, v := values[i], values[i+1]
+			pairs++
+
+			if (v%2 == 0 && u < v) || (v%2 == 1 && u > v) {
+				x++
+			}
+
+			if (v%2 == 0 && u > v) || (v%2 == 1 && u < v) {
+				y++
+			}
+
+			if u/2 == v/2 {
+				k++
+			}
+		}
+	}
+
+	a := k / 2
+	b := 2*x - pairs
+	c := y - x
+
+	if a == 0 {
+		if b == 0 {
+			return 0
+		}
+		return clampRate(-c / b)
+	}
+
+	disc := b*b - 4*a*c
+	if disc < 0 {
+		disc = 0
+	}
+
+	r1 := (-b + math.Sqrt(disc)) / (2 * a)
+	r2 := (-b - math.Sqrt(disc)) / (2 * a)
+
+	return clampRate(min(r1, r2))
+}
@@ -0,0 +1,470 @@
+package stegano
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	c "github.com/scott-mescudi/stegano/compression"
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// FileInfo describes a file stored in an embedded archive.
+type FileInfo struct {
+	Name    string
+	Size    int64
+	Mode    fs.FileMode
+	ModTime time.Time
+	SHA256  [32]byte
+}
+
+// File is a file read from an embedded archive.
+type File struct {
+	FileInfo
+	Data []byte
+}
+
+// OverwritePolicy decides what happens when an extracted file already exists on disk.
+type OverwritePolicy uint8
+
+const (
+	// OverwriteFail stops extraction with ErrFileExists.
+	OverwriteFail OverwritePolicy = iota
+	// OverwriteRename writes the file as "name (1).ext", "name (2).ext" and so on.
+	OverwriteRename
+	// OverwriteReplace replaces the existing file.
+	OverwriteReplace
+)
+
+func fileInfoFromEntry(e u.ArchiveEntry) FileInfo {
+	return FileInfo{Name: e.Name, Size: e.Size, Mode: e.Mode, ModTime: e.ModTime, SHA256: e.SHA256}
+}
+
+// imageCapacityBits returns the number of bits the image at path can hold at bitDepth,
+// reading only the image header so oversized payloads are rejected before the image is decoded.
+func imageCapacityBits(path string, bitDepth uint8) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	cfg, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return 0, err
+	}
+
+	return cfg.Width * cfg.Height * 3 * (int(bitDepth) + 1), nil
+}
+
+// embedArchive compresses, encrypts and embeds the archive of entries into the cover image and saves the result.
+func embedArchive(coverImagePath string, entries []u.ArchiveEntry, outputFilePath, password string, bitDepth uint8, o *options) error {
+	if coverImagePath == "" {
+		return errors.New("invalid coverImagePath")
+	}
+
+	if outputFilePath == "" {
+		return errors.New("invalid outputFilePath")
+	}
+
+	if password == "" {
+		return errors.New("invalid password")
+	}
+
+	if bitDepth > 7 {
+		return ErrDepthOutOfRange
+	}
+
+	if ext := filepath.Ext(outputFilePath); ext != ".png" {
+		return fmt.Errorf("output file must have a .png extension, got '%s'", ext)
+	}
+
+	capacity, err := imageCapacityBits(coverImagePath, bitDepth)
+	if err != nil {
+		return err
+	}
+
+	archive, err := u.MarshalArchive(entries)
+	if err != nil {
+		return err
+	}
+
+	compressed, err := compressData(archive, o)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToCompressData, err)
+	}
+
+	cipherText, err := u.Encrypt(password, compressed)
+	if err != nil {
+		return err
+	}
+
+	if (len(cipherText)*8)+32 > capacity {
+		return ErrDataTooLarge
+	}
+
+	cf, err := Decodeimage(coverImagePath)
+	if err != nil {
+		return err
+	}
+
+	channels := u.ExtractRGBChannelsFromImageWithConCurrency(cf, runtime.NumCPU())
+
+	channels, err = u.EmbedIntoRGBchannelsWithDepthMonitored(channels, cipherText, bitDepth, o.monitor())
+	if err != nil {
+		return err
+	}
+
+	newImage, err := u.SaveImage(channels, cf.Bounds().Dy(), cf.Bounds().Dx())
+	if err != nil {
+		return ErrFailedToSaveImage
+	}
+
+	return SaveImage(outputFilePath, newImage)
+}
+
+// readArchive extracts, decrypts and decompresses the archive embedded in the image.
+func readArchive(coverImagePath, password string, bitDepth uint8) ([]u.ArchiveEntry, error) {
+	if coverImagePath == "" {
+		return nil, errors.New("invalid coverImagePath")
+	}
+
+	if password == "" {
+		return nil, errors.New("invalid password")
+	}
+
+	if bitDepth > 7 {
+		return nil, ErrDepthOutOfRange
+	}
+
+	cf, err := Decodeimage(coverImagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := u.ExtractRGBChannelsFromImageWithConCurrency(cf, runtime.NumCPU())
+	embeddedData, err := u.ExtractDataFromRGBchannelsWithDepth(channels, bitDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherText, err := extractPayload(embeddedData, applyOptions(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := u.Decrypt(password, cipherText)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := c.Decompress(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := u.UnmarshalArchive(archive)
+	if errors.Is(err, u.ErrInvalidArchive) && bytes.HasPrefix(archive, []byte("/-")) {
+		return legacyArchive(archive)
+	}
+
+	return entries, err
+}
+
+// legacyArchive reads the single file stored by EmbedFile before archives were introduced:
+// a "/-name-/" line followed by the contents of the file.
+func legacyArchive(data []byte) ([]u.ArchiveEntry, error) {
+	line, contents, ok := bytes.Cut(data, []byte("\n"))
+	if !ok || len(line) < 5 || !bytes.HasPrefix(line, []byte("/-")) || !bytes.HasSuffix(line, []byte("-/")) {
+		return nil, u.ErrInvalidArchive
+	}
+
+	name := string(line[2 : len(line)-2])
+	return []u.ArchiveEntry{u.NewArchiveEntry(name, 0o644, time.Time{}, contents)}, nil
+}
+
+// EmbedFiles embeds several files into an image as a single archive holding each file's name, size,
+// permissions, modification time and SHA-256. The archive is compressed and encrypted with the password.
+// Files are stored under their base name, which must be unique.
+//
+// Parameters:
+// - coverImagePath: The file path of the image to embed the files into.
+// - dataFilePaths: The file paths of the files to embed.
+// - outputFilePath: The file path to save the resulting image with embedded data.
+// - password: A password used to encrypt the archive before embedding.
+// - bitDepth: The bit depth used for embedding (valid range: 0-7).
+func EmbedFiles(coverImagePath string, dataFilePaths []string, outputFilePath, password string, bitDepth uint8) error {
+	if len(dataFilePaths) == 0 {
+		return errors.New("invalid dataFilePaths")
+	}
+
+	entries := make([]u.ArchiveEntry, 0, len(dataFilePaths))
+	seen := make(map[string]bool, len(dataFilePaths))
+	for _, path := range dataFilePaths {
+		if path == "" {
+			return errors.New("invalid dataFilePath")
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("'%s' is not a regular file", path)
+		}
+
+		name := filepath.Base(path)
+		if seen[name] {
+			return fmt.Errorf("%w: %s", ErrDuplicateFileName, name)
+		}
+		seen[name] = true
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, u.NewArchiveEntry(name, info.Mode().Perm(), info.ModTime(), data))
+	}
+
+	return embedArchive(coverImagePath, entries, outputFilePath, password, bitDepth, applyOptions(nil))
+}
+
+// ListFiles returns the metadata of the files embedded with EmbedFiles without writing anything to disk.
+//
+// Parameters:
+// - coverImagePath: The file path of the image containing the archive.
+// - password: The password used when embedding.
+// - bitDepth: The bit depth used when embedding.
+func ListFiles(coverImagePath, password string, bitDepth uint8) ([]FileInfo, error) {
+	entries, err := readArchive(coverImagePath, password, bitDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]FileInfo, len(entries))
+	for i, e := range entries {
+		infos[i] = fileInfoFromEntry(e)
+	}
+
+	return infos, nil
+}
+
+// ExtractFiles returns the files embedded with EmbedFiles in memory. If names are given only those
+// files are returned, in archive order, and a name that is not in the archive is an error.
+//
+// Parameters:
+// - coverImagePath: The file path of the image containing the archive.
+// - password: The password used when embedding.
+// - bitDepth: The bit depth used when embedding.
+// - names: The names of the files to return, all files if empty.
+func ExtractFiles(coverImagePath, password string, bitDepth uint8, names ...string) ([]File, error) {
+	entries, err := readArchive(coverImagePath, password, bitDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = false
+	}
+
+	var files []File
+	for _, e := range entries {
+		if len(names) > 0 {
+			if _, ok := wanted[e.Name]; !ok {
+				continue
+			}
+			wanted[e.Name] = true
+		}
+
+		files = append(files, File{FileInfo: fileInfoFromEntry(e), Data: e.Data})
+	}
+
+	for _, name := range names {
+		if !wanted[name] {
+			return nil, fmt.Errorf("%w: %s", ErrFileNotInArchive, name)
+		}
+	}
+
+	return files, nil
+}
+
+// safePath joins a file name from an archive to outputDir, rejecting absolute names and names escaping outputDir.
+func safePath(outputDir, name string) (string, error) {
+	local := filepath.FromSlash(name)
+	if !filepath.IsLocal(local) {
+		return "", fmt.Errorf("%w: %q", ErrUnsafeFileName, name)
+	}
+
+	return filepath.Join(outputDir, local), nil
+}
+
+// availablePath returns path, or the first "name (n).ext" variant of it that does not exist yet.
+func availablePath(path string) string {
+	ext := filepath.Ext(path)
+	base := path[:len(path)-len(ext)]
+	for n := 1; ; n++ {
+		if _, err := os.Lstat(path); errors.Is(err, fs.ErrNotExist) {
+			return path
+		}
+		path = fmt.Sprintf("%s (%d)%s", base, n, ext)
+	}
+}
+
+// resolvesInside checks that path, with symlinks resolved as far as it exists, stays below root.
+// root must already be resolved.
+func resolvesInside(root, path string) error {
+	existing := path
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return err
+	}
+
+	if rel, err := filepath.Rel(root, resolved); err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("%w: %s resolves outside the output directory", ErrUnsafeFileName, path)
+	}
+
+	return nil
+}
+
+// mkdirInside creates dir and its parents with perm, refusing to follow symlinks out of root.
+func mkdirInside(root, dir string, perm fs.FileMode) error {
+	if err := resolvesInside(root, dir); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, perm); err != nil {
+		return err
+	}
+
+	return resolvesInside(root, dir)
+}
+
+func writeFile(root, path string, f File, policy OverwritePolicy) (string, error) {
+	if err := mkdirInside(root, filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	switch policy {
+	case OverwriteRename:
+		path = availablePath(path)
+	case OverwriteReplace:
+		// remove instead of truncating so an existing symlink is replaced rather than followed
+		if info, err := os.Lstat(path); err == nil && !info.IsDir() {
+			if err := os.Remove(path); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, f.Mode.Perm())
+	if errors.Is(err, fs.ErrExist) {
+		return "", fmt.Errorf("%w: %s", ErrFileExists, path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := out.Write(f.Data); err != nil {
+		out.Close()
+		return "", err
+	}
+
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+
+	if !f.ModTime.IsZero() {
+		if err := os.Chtimes(path, f.ModTime, f.ModTime); err != nil {
+			return "", err
+		}
+	}
+
+	return path, nil
+}
+
+// WriteFiles writes files read with ExtractFiles below outputDir and returns the paths written.
+// Directory entries, as stored by EmbedDirectory, are created with their permissions and modification time.
+// Every name is checked before anything is written, names that are absolute or escape outputDir
+// fail with ErrUnsafeFileName. So do names leading through a symlink below outputDir that points outside it.
+// Existing files are handled according to policy.
+//
+// Parameters:
+// - files: The files to write.
+// - outputDir: The directory to write to, created if missing.
+// - policy: What to do when a file already exists.
+func WriteFiles(files []File, outputDir string, policy OverwritePolicy) ([]string, error) {
+	if outputDir == "" {
+		outputDir = "."
+	}
+
+	if policy > OverwriteReplace {
+		return nil, fmt.Errorf("invalid overwrite policy %d", policy)
+	}
+
+	paths := make([]string, len(files))
+	for i, f := range files {
+		path, err := safePath(outputDir, f.Name)
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = path
+	}
+
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	root, err := filepath.EvalSymlinks(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []int
+	for i, f := range files {
+		if f.Mode.IsDir() {
+			if err := mkdirInside(root, paths[i], f.Mode.Perm()|0o700); err != nil {
+				return paths[:i], err
+			}
+			dirs = append(dirs, i)
+			continue
+		}
+
+		path, err := writeFile(root, paths[i], f, policy)
+		if err != nil {
+			return paths[:i], err
+		}
+		paths[i] = path
+	}
+
+	// directory times are restored last, writing the files below them changes them
+	for i := len(dirs) - 1; i >= 0; i-- {
+		f := files[dirs[i]]
+		if !f.ModTime.IsZero() {
+			if err := os.Chtimes(paths[dirs[i]], f.ModTime, f.ModTime); err != nil {
+				return paths, err
+			}
+		}
+	}
+
+	return paths, nil
+}
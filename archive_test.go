@@ -0,0 +1,306 @@
+package stegano
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	c "github.com/scott-mescudi/stegano/compression"
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+func writeTestFiles(t *testing.T, files map[string]string) (string, []string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	var paths []string
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(contents), 0o640); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	return dir, paths
+}
+
+func saveTestCover(t *testing.T, dir string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "cover.png")
+	if err := SaveImage(path, createTestImage()); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestEmbedFiles(t *testing.T) {
+	contents := map[string]string{"a.txt": "first file", "b.txt": "second file", "c.bin": "\x00\x01\x02"}
+	dir, paths := writeTestFiles(t, contents)
+	cover := saveTestCover(t, dir)
+	output := filepath.Join(dir, "out.png")
+
+	if err := EmbedFiles(cover, paths, output, "password", 1); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	infos, err := ListFiles(output, "password", 1)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(infos) != len(contents) {
+		t.Fatalf("expected %d files, got %d", len(contents), len(infos))
+	}
+
+	for _, info := range infos {
+		if int(info.Size) != len(contents[info.Name]) || info.Mode.Perm() != 0o640 || info.ModTime.IsZero() {
+			t.Fatalf("unexpected file info %+v", info)
+		}
+	}
+
+	files, err := ExtractFiles(output, "password", 1, "b.txt")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(files) != 1 || files[0].Name != "b.txt" || !bytes.Equal(files[0].Data, []byte("second file")) {
+		t.Fatalf("unexpected files %+v", files)
+	}
+
+	if _, err := ExtractFiles(output, "password", 1, "missing.txt"); !errors.Is(err, ErrFileNotInArchive) {
+		t.Fatalf("expected error: %v, got: %v", ErrFileNotInArchive, err)
+	}
+
+	if _, err := ListFiles(output, "wrong password", 1); err == nil {
+		t.Fatal("expected error for wrong password")
+	}
+}
+
+func TestEmbedFiles_DuplicateName(t *testing.T) {
+	dir, paths := writeTestFiles(t, map[string]string{"a.txt": "a"})
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	dup := filepath.Join(sub, "a.txt")
+	if err := os.WriteFile(dup, []byte("b"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := EmbedFiles(saveTestCover(t, dir), append(paths, dup), filepath.Join(dir, "out.png"), "password", 1)
+	if !errors.Is(err, ErrDuplicateFileName) {
+		t.Fatalf("expected error: %v, got: %v", ErrDuplicateFileName, err)
+	}
+}
+
+func TestEmbedFileExtractFile(t *testing.T) {
+	dir, paths := writeTestFiles(t, map[string]string{"single.txt": "single file"})
+	cover := saveTestCover(t, dir)
+	output := filepath.Join(dir, "out.png")
+
+	if err := EmbedFile(cover, paths[0], output, "password", 0); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	extractDir := filepath.Join(dir, "extracted")
+	if err := ExtractFile(output, "password", 0, WithOutputDir(extractDir)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if err := ExtractFile(output, "password", 0, WithOutputDir(extractDir)); !errors.Is(err, ErrFileExists) {
+		t.Fatalf("expected error: %v, got: %v", ErrFileExists, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(extractDir, "single.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "single file" {
+		t.Fatalf("expected %q, got %q", "single file", data)
+	}
+}
+
+// saveLegacyFile embeds contents the way EmbedFile did before archives were introduced:
+// a "/-name-/" line followed by the contents, compressed with zstd and encrypted.
+func saveLegacyFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+
+	compressed, err := c.CompressZSTD([]byte("/-" + name + "-/\n" + contents))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cipherText, err := u.Encrypt("password", compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	channels := u.ExtractRGBChannelsFromImageWithConCurrency(createTestImage(), 1)
+	channels, err = u.EmbedIntoRGBchannelsWithDepth(channels, cipherText, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := u.SaveImage(channels, 100, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "legacy.png")
+	if err := SaveImage(path, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestExtractFile_Legacy(t *testing.T) {
+	dir := t.TempDir()
+	output := saveLegacyFile(t, dir, "old.txt", "written by the old EmbedFile\nsecond line")
+
+	extractDir := filepath.Join(dir, "extracted")
+	if err := ExtractFile(output, "password", 0, WithOutputDir(extractDir)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(extractDir, "old.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "written by the old EmbedFile\nsecond line" {
+		t.Fatalf("expected %q, got %q", "written by the old EmbedFile\nsecond line", data)
+	}
+
+	infos, err := ListFiles(output, "password", 0)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(infos) != 1 || infos[0].Name != "old.txt" {
+		t.Fatalf("expected a single entry old.txt, got %+v", infos)
+	}
+
+	unsafe := saveLegacyFile(t, dir, "../escape.txt", "evil")
+	if err := ExtractFile(unsafe, "password", 0, WithOutputDir(extractDir)); !errors.Is(err, ErrUnsafeFileName) {
+		t.Fatalf("expected error: %v, got: %v", ErrUnsafeFileName, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "escape.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing written outside the output directory, got: %v", err)
+	}
+}
+
+func TestWriteFiles_UnsafeNames(t *testing.T) {
+	dir := t.TempDir()
+	outputDir := filepath.Join(dir, "out")
+
+	for _, name := range []string{"../escape.txt", "/etc/passwd", "a/../../escape.txt", ""} {
+		files := []File{{FileInfo: FileInfo{Name: "ok.txt"}, Data: []byte("ok")}, {FileInfo: FileInfo{Name: name}, Data: []byte("evil")}}
+		if _, err := WriteFiles(files, outputDir, OverwriteReplace); !errors.Is(err, ErrUnsafeFileName) {
+			t.Fatalf("name %q: expected error: %v, got: %v", name, ErrUnsafeFileName, err)
+		}
+	}
+
+	// names are checked before anything is written
+	if _, err := os.Stat(filepath.Join(outputDir, "ok.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing to be written, got: %v", err)
+	}
+}
+
+func TestWriteFiles_Symlink(t *testing.T) {
+	dir := t.TempDir()
+	outputDir := filepath.Join(dir, "out")
+	outside := filepath.Join(dir, "outside")
+	for _, d := range []string{filepath.Join(outputDir, "inside"), outside} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.Symlink(outside, filepath.Join(outputDir, "escape")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := os.Symlink(filepath.Join(outputDir, "inside"), filepath.Join(outputDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []File{
+		{FileInfo: FileInfo{Name: "escape/evil.txt"}, Data: []byte("evil")},
+		{FileInfo: FileInfo{Name: "escape/sub/evil.txt"}, Data: []byte("evil")},
+		{FileInfo: FileInfo{Name: "escape/dir", Mode: fs.ModeDir | 0o755}},
+	} {
+		if _, err := WriteFiles([]File{f}, outputDir, OverwriteReplace); !errors.Is(err, ErrUnsafeFileName) {
+			t.Fatalf("name %q: expected error: %v, got: %v", f.Name, ErrUnsafeFileName, err)
+		}
+	}
+
+	if entries, err := os.ReadDir(outside); err != nil || len(entries) != 0 {
+		t.Fatalf("expected nothing written outside the output directory, got %v (%v)", entries, err)
+	}
+
+	// a symlink that stays below the output directory is fine
+	files := []File{{FileInfo: FileInfo{Name: "link/ok.txt"}, Data: []byte("ok")}}
+	if _, err := WriteFiles(files, outputDir, OverwriteFail); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(outputDir, "inside", "ok.txt")); err != nil || string(data) != "ok" {
+		t.Fatalf("expected %q, got %q (%v)", "ok", data, err)
+	}
+}
+
+func TestWriteFiles_OverwritePolicy(t *testing.T) {
+	dir := t.TempDir()
+	files := []File{{FileInfo: FileInfo{Name: "sub/file.txt", Mode: 0o600}, Data: []byte("new")}}
+
+	if _, err := WriteFiles(files, dir, OverwriteFail); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		policy   OverwritePolicy
+		wantErr  error
+		wantPath string
+	}{
+		{name: "Fail", policy: OverwriteFail, wantErr: ErrFileExists},
+		{name: "Rename", policy: OverwriteRename, wantPath: filepath.Join(dir, "sub", "file (1).txt")},
+		{name: "Rename again", policy: OverwriteRename, wantPath: filepath.Join(dir, "sub", "file (2).txt")},
+		{name: "Replace", policy: OverwriteReplace, wantPath: filepath.Join(dir, "sub", "file.txt")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := WriteFiles(files, dir, tt.policy)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected error: %v, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if paths[0] != tt.wantPath {
+				t.Fatalf("expected %s, got %s", tt.wantPath, paths[0])
+			}
+
+			data, err := os.ReadFile(paths[0])
+			if err != nil || string(data) != "new" {
+				t.Fatalf("expected %q, got %q (%v)", "new", data, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package stegano
 
 import (
+	"context"
 	"fmt"
 
 	c "github.com/scott-mescudi/stegano/compression"
@@ -8,12 +9,20 @@ import (
 )
 
 // EmbedDataIntoWAVWithDepth embeds compressed data into a WAV file with a specified bit depth.
-func (s *AudioEmbedHandler) EmbedIntoWAVWithDepth(audioFilename, outputFilename string, data []byte, bitDepth uint8) error {
+func (s *AudioEmbedHandler) EmbedIntoWAVWithDepth(audioFilename, outputFilename string, data []byte, bitDepth uint8, opts ...Option) error {
+	return s.EmbedIntoWAVWithDepthContext(context.Background(), audioFilename, outputFilename, data, bitDepth, opts...)
+}
+
+// EmbedIntoWAVWithDepthContext is like EmbedIntoWAVWithDepth but stops with the context's error once ctx is cancelled.
+func (s *AudioEmbedHandler) EmbedIntoWAVWithDepthContext(ctx context.Context, audioFilename, outputFilename string, data []byte, bitDepth uint8, opts ...Option) error {
 	if bitDepth >= 8 {
 		return ErrDepthOutOfRange
 	}
 
-	nd, err := c.CompressZSTD(data)
+	o := applyOptions(opts)
+	o.ctx = ctx
+
+	nd, err := compressData(data, o)
 	if err != nil {
 		return err
 	}
@@ -24,11 +33,15 @@ func (s *AudioEmbedHandler) EmbedIntoWAVWithDepth(audioFilename, outputFilename
 		return err
 	}
 
-	buffer, err = u.EmbedDataWithDepthAudio(buffer, nd, bitDepth)
+	buffer, err = u.EmbedDataWithDepthAudioMonitored(buffer, nd, bitDepth, o.monitor())
 	if err != nil {
 		return err
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	err = SaveAudioToFile(outputFilename, decoder, buffer)
 	if err != nil {
 		return err
@@ -38,18 +51,29 @@ func (s *AudioEmbedHandler) EmbedIntoWAVWithDepth(audioFilename, outputFilename
 }
 
 // ExtractDataFromWAVWithDepth extracts compressed data from a WAV file with a specified bit depth.
-func (s *AudioExtractHandler) ExtractFromWAVWithDepth(audioFilename string, bitDepth uint8) ([]byte, error) {
+func (s *AudioExtractHandler) ExtractFromWAVWithDepth(audioFilename string, bitDepth uint8, opts ...Option) ([]byte, error) {
+	return s.ExtractFromWAVWithDepthContext(context.Background(), audioFilename, bitDepth, opts...)
+}
+
+// ExtractFromWAVWithDepthContext is like ExtractFromWAVWithDepth but stops with the context's error once ctx is cancelled.
+func (s *AudioExtractHandler) ExtractFromWAVWithDepthContext(ctx context.Context, audioFilename string, bitDepth uint8, opts ...Option) ([]byte, error) {
 	if bitDepth >= 8 {
 		return nil, ErrDepthOutOfRange
 	}
 
+	o := applyOptions(opts)
+	o.ctx = ctx
+
 	decoder := LoadAudioData(audioFilename)
 	buffer, err := decoder.FullPCMBuffer()
 	if err != nil {
 		return nil, err
 	}
 
-	data := u.ExtractDataWithDepthAudio(buffer, bitDepth)
+	data, err := u.ExtractDataWithDepthAudioMonitored(buffer, bitDepth, o.monitor())
+	if err != nil {
+		return nil, err
+	}
 
 	lenData, err := u.GetlenOfData(data)
 	if err != nil {
@@ -71,7 +95,7 @@ func (s *AudioExtractHandler) ExtractFromWAVWithDepth(audioFilename string, bitD
 		moddedData = append(moddedData, data[i])
 	}
 
-	nd, err := c.DecompressZSTD(moddedData)
+	nd, err := c.Decompress(moddedData)
 	if err != nil {
 		return nil, err
 	}
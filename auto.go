@@ -0,0 +1,350 @@
+package stegano
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"slices"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// autoOrder returns the samples the payload is written to, skipping the ones holding the depth header.
+func autoOrder(RGBchannels []u.RgbChannel, width, height int, bitDepth uint8, adaptive, ecc bool) ([]int, error) {
+	if !adaptive {
+		if ecc {
+			return u.SpreadOrder(u.AutoHeaderSamples, len(RGBchannels)*3), nil
+		}
+		return u.AutoOrder(RGBchannels), nil
+	}
+
+	order, err := adaptiveOrder(RGBchannels, width, height, bitDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(order, func(k int) bool { return k < u.AutoHeaderSamples }), nil
+}
+
+// embedAuto writes payload at the smallest bit depth it fits in and records the depth and mode in the header.
+func embedAuto(RGBchannels []u.RgbChannel, payload []byte, width, height int, o *options) ([]u.RgbChannel, error) {
+	var stream []byte
+	if o.errorCorrection {
+		encoded, err := u.ECCEncode(payload)
+		if err != nil {
+			return nil, err
+		}
+		stream = encoded
+	} else {
+		stream = binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+		stream = append(stream, payload...)
+	}
+
+	samples := len(RGBchannels)*3 - u.AutoHeaderSamples
+	bitDepth := LSB
+	for samples*(int(bitDepth)+1)/8 < len(stream) {
+		if bitDepth == MaxBitDepth {
+			return nil, ErrDataTooLarge
+		}
+		bitDepth++
+	}
+
+	if o.noiseFill {
+		noise, err := u.NewNoiseReader(o.noiseKey, RGBchannels, bitDepth)
+		if err != nil {
+			return nil, err
+		}
+
+		padding := make([]byte, samples*(int(bitDepth)+1)/8-len(stream))
+		if _, err := io.ReadFull(noise, padding); err != nil {
+			return nil, err
+		}
+		stream = append(stream, padding...)
+	}
+
+	return recordStats(RGBchannels, width, o, func() ([]u.RgbChannel, error) {
+		order, err := autoOrder(RGBchannels, width, height, bitDepth, o.leastDistortion, o.errorCorrection)
+		if err != nil {
+			return nil, err
+		}
+
+		embedded, err := u.EmbedRawIntoRGBchannelsWithOrderMonitored(RGBchannels, stream, bitDepth, order, o.monitor())
+		if err != nil {
+			return nil, err
+		}
+
+		if err := u.EmbedAutoHeader(embedded, bitDepth, o.leastDistortion, o.errorCorrection); err != nil {
+			return nil, err
+		}
+
+		return embedded, nil
+	})
+}
+
+// extractAuto reads the depth header and returns the payload written by embedAuto.
+// Whether the payload is error corrected is taken from the header, not from o.
+func extractAuto(RGBchannels []u.RgbChannel, width, height int, o *options) ([]byte, error) {
+	bitDepth, adaptive, ecc, err := u.ExtractAutoHeader(RGBchannels)
+	if err != nil {
+		return nil, ErrNoDepthHeader
+	}
+	o.errorCorrection = ecc
+
+	order, err := autoOrder(RGBchannels, width, height, bitDepth, adaptive, ecc)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := u.ExtractRawFromRGBchannelsWithOrderMonitored(RGBchannels, bitDepth, order, o.monitor())
+	if err != nil {
+		if o.ctx != nil && o.ctx.Err() != nil {
+			return nil, o.ctx.Err()
+		}
+		return nil, ErrFailedToExtractData
+	}
+
+	return extractPayload(data, o)
+}
+
+// EncodeAuto works like Encode but picks the smallest bit depth that fits the data after compression and
+// the overhead of the given options. The depth is stored in a header in the first pixels so DecodeAuto
+// finds it without being told. Pass WithLeastDistortion to also place the data in textured areas first.
+//
+// Parameters:
+// - coverImage: The original image where data will be embedded.
+// - data: The data to embed into the image.
+// - outputFilename: The name of the file where the modified image will be saved.
+// - defaultCompression: A flag indicating whether the data should be compressed before embedding.
+// - opts: Optional settings such as WithLeastDistortion, WithErrorCorrection, WithProgress or WithStats.
+func (m *EmbedHandler) EncodeAuto(coverImage image.Image, data []byte, outputFilename string, defaultCompression bool, opts ...Option) error {
+	return m.EncodeAutoContext(context.Background(), coverImage, data, outputFilename, defaultCompression, opts...)
+}
+
+// EncodeAutoContext is like EncodeAuto but stops with the context's error once ctx is cancelled.
+func (m *EmbedHandler) EncodeAutoContext(ctx context.Context, coverImage image.Image, data []byte, outputFilename string, defaultCompression bool, opts ...Option) error {
+	if coverImage == nil {
+		return ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return ErrInvalidCoverImage
+	}
+
+	if len(data) == 0 {
+		return ErrInvalidData
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return ErrFailedToExtractRGB
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	o := applyOptions(opts)
+	o.ctx = ctx
+
+	indata, err := encodePayload(data, defaultCompression, o)
+	if err != nil {
+		return err
+	}
+
+	embeddedRGBChannels, err := embedAuto(RGBchannels, indata, width, height, o)
+	if err != nil {
+		return fmt.Errorf("failed to embed data into RGB channels: %w", err)
+	}
+
+	imgdata, err := u.SaveImage(embeddedRGBChannels, height, width)
+	if err != nil {
+		return ErrFailedToSaveImage
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if outputFilename == "" {
+		outputFilename = DefaultOutputFile
+	}
+
+	return SaveImage(outputFilename, imgdata)
+}
+
+// DecodeAuto extracts data embedded with EncodeAuto, reading the bit depth and mode from the image.
+//
+// Parameters:
+// - coverImage: The image containing embedded data to be extracted.
+// - opts: Optional settings such as WithProgress. The bit depth, mode and error correction are read from the image.
+func (m *ExtractHandler) DecodeAuto(coverImage image.Image, opts ...Option) ([]byte, error) {
+	return m.DecodeAutoContext(context.Background(), coverImage, opts...)
+}
+
+// DecodeAutoContext is like DecodeAuto but stops with the context's error once ctx is cancelled.
+func (m *ExtractHandler) DecodeAutoContext(ctx context.Context, coverImage image.Image, opts ...Option) ([]byte, error) {
+	if coverImage == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	o := applyOptions(opts)
+	o.ctx = ctx
+
+	moddedData, err := extractAuto(RGBchannels, width, height, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodePayload(moddedData)
+}
+
+// EncodeAuto works like Encode but picks the smallest bit depth that fits the data after encryption,
+// compression and Reed-Solomon encoding, and stores it in a header for DecodeAuto.
+//
+// Parameters:
+// - coverImage: The image to embed data into.
+// - data: The data to embed in the image.
+// - outputFilename: The file name to save the resulting image. Defaults to a pre-defined name if empty.
+// - password: The password used to encrypt the data.
+// - opts: Optional settings such as WithLeastDistortion, WithReedSolomon, WithErrorCorrection, WithProgress or WithStats.
+func (m *SecureEmbedHandler) EncodeAuto(coverImage image.Image, data []byte, outputFilename string, password string, opts ...Option) error {
+	return m.EncodeAutoContext(context.Background(), coverImage, data, outputFilename, password, opts...)
+}
+
+// EncodeAutoContext is like EncodeAuto but stops with the context's error once ctx is cancelled.
+func (m *SecureEmbedHandler) EncodeAutoContext(ctx context.Context, coverImage image.Image, data []byte, outputFilename string, password string, opts ...Option) error {
+	if coverImage == nil {
+		return ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return ErrInvalidCoverImage
+	}
+
+	if len(data) == 0 {
+		return ErrInvalidData
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return ErrFailedToExtractRGB
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	o := applyOptions(opts)
+	o.ctx = ctx
+
+	compressedData, err := compressData(data, o)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToCompressData, err)
+	}
+
+	cipher, err := EncryptData(compressedData, password)
+	if err != nil {
+		return err
+	}
+
+	RsData, err := u.RsEncodeShards(cipher, o.dataShards, o.parityShards)
+	if err != nil {
+		return err
+	}
+
+	embeddedRGBChannels, err := embedAuto(RGBchannels, RsData, width, height, o)
+	if err != nil {
+		return fmt.Errorf("failed to embed data into RGB channels: %w", err)
+	}
+
+	imgdata, err := u.SaveImage(embeddedRGBChannels, height, width)
+	if err != nil {
+		return ErrFailedToSaveImage
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if outputFilename == "" {
+		outputFilename = DefaultOutputFile
+	}
+
+	return SaveImage(outputFilename, imgdata)
+}
+
+// DecodeAuto extracts and decrypts data embedded with SecureEmbedHandler.EncodeAuto.
+//
+// Parameters:
+// - coverImage: The image containing the embedded data.
+// - password: The password used to decrypt the embedded data.
+// - opts: Optional settings such as WithProgress. The bit depth, mode and error correction are read from the image.
+func (m *SecureExtractHandler) DecodeAuto(coverImage image.Image, password string, opts ...Option) ([]byte, error) {
+	return m.DecodeAutoContext(context.Background(), coverImage, password, opts...)
+}
+
+// DecodeAutoContext is like DecodeAuto but stops with the context's error once ctx is cancelled.
+func (m *SecureExtractHandler) DecodeAutoContext(ctx context.Context, coverImage image.Image, password string, opts ...Option) ([]byte, error) {
+	if coverImage == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	o := applyOptions(opts)
+	o.ctx = ctx
+
+	moddedData, err := extractAuto(RGBchannels, width, height, o)
+	if err != nil {
+		return nil, err
+	}
+
+	RsUnpacked, err := decodeShards(moddedData)
+	if err != nil {
+		return nil, err
+	}
+
+	return decryptData(RsUnpacked, password)
+}
@@ -0,0 +1,147 @@
+package stegano
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"math/rand"
+	"os"
+	"testing"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+func autoDepth(t *testing.T, filename string) (uint8, bool, bool) {
+	t.Helper()
+
+	img, err := Decodeimage(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	depth, adaptive, ecc, err := u.ExtractAutoHeader(u.ExtractRGBChannelsFromImageWithConCurrency(img, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return depth, adaptive, ecc
+}
+
+func TestEncodeAuto(t *testing.T) {
+	cover := createGradientImage(64, 64)
+	random := make([]byte, 3000)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		opts     []Option
+		depth    uint8
+		adaptive bool
+		ecc      bool
+	}{
+		{name: "Small payload", data: []byte("fits in the LSB"), depth: 0},
+		{name: "Large payload", data: random, depth: 1},
+		{name: "Least distortion", data: random, opts: []Option{WithLeastDistortion()}, depth: 1, adaptive: true},
+		{name: "Error correction overhead", data: random[:1400], opts: []Option{WithErrorCorrection()}, depth: 1, ecc: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFilename := "test_auto_output.png"
+			defer os.Remove(outputFilename)
+
+			if err := NewEmbedHandler().EncodeAuto(cover, tt.data, outputFilename, false, tt.opts...); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			depth, adaptive, ecc := autoDepth(t, outputFilename)
+			if depth != tt.depth || adaptive != tt.adaptive || ecc != tt.ecc {
+				t.Fatalf("expected depth %d adaptive %v ecc %v, got %d %v %v", tt.depth, tt.adaptive, tt.ecc, depth, adaptive, ecc)
+			}
+
+			img, err := Decodeimage(outputFilename)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			extracted, err := NewExtractHandler().DecodeAuto(img, tt.opts...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if !bytes.Equal(extracted, tt.data) {
+				t.Fatal("extracted data does not match")
+			}
+		})
+	}
+}
+
+func TestDecodeAuto_ErrorCorrectionFromHeader(t *testing.T) {
+	outputFilename := "test_auto_ecc_output.png"
+	defer os.Remove(outputFilename)
+	data := []byte("error correction is recorded in the header")
+
+	if err := NewEmbedHandler().EncodeAuto(createTestImage(), data, outputFilename, false, WithErrorCorrection()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	img, err := Decodeimage(outputFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Flip the green LSB along a row past the header, which only error correction can repair
+	stego := img.(*image.RGBA)
+	for x := 0; x < stego.Bounds().Dx(); x++ {
+		c := stego.RGBAAt(x, 50)
+		c.G ^= 1
+		stego.SetRGBA(x, 50, c)
+	}
+
+	extracted, err := NewExtractHandler().DecodeAuto(stego)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if !bytes.Equal(extracted, data) {
+		t.Fatalf("expected %q, got %q", data, extracted)
+	}
+}
+
+func TestEncodeAuto_TooLarge(t *testing.T) {
+	err := NewEmbedHandler().EncodeAuto(createGradientImage(16, 16), make([]byte, 1000), "test_auto_output.png", false)
+	if !errors.Is(err, ErrDataTooLarge) {
+		t.Fatalf("expected error: %v, got: %v", ErrDataTooLarge, err)
+	}
+}
+
+func TestDecodeAuto_NoHeader(t *testing.T) {
+	if _, err := NewExtractHandler().DecodeAuto(createTestImage()); !errors.Is(err, ErrNoDepthHeader) {
+		t.Fatalf("expected error: %v, got: %v", ErrNoDepthHeader, err)
+	}
+}
+
+func TestSecureEncodeAuto(t *testing.T) {
+	outputFilename := "test_secure_auto_output.png"
+	defer os.Remove(outputFilename)
+	data := []byte("secret data with automatic depth")
+
+	if err := NewSecureEmbedHandler().EncodeAuto(createTestImage(), data, outputFilename, "password", WithLeastDistortion()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	img, err := Decodeimage(outputFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extracted, err := NewSecureExtractHandler().DecodeAuto(img, "password")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if !bytes.Equal(extracted, data) {
+		t.Fatalf("expected %q, got %q", data, extracted)
+	}
+}
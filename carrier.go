@@ -0,0 +1,187 @@
+package stegano
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"runtime"
+	"slices"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+)
+
+// Carrier is a cover medium that data can be embedded into, used to spread one payload over several files.
+type Carrier interface {
+	// Capacity returns the number of bytes that can be embedded at the given bit depth.
+	Capacity(bitDepth uint8) int
+	// Embed returns a copy of the carrier with data embedded at the given bit depth.
+	Embed(data []byte, bitDepth uint8) (Carrier, error)
+	// Extract returns the data embedded at the given bit depth.
+	Extract(bitDepth uint8) ([]byte, error)
+}
+
+// ImageCarrier embeds data into the RGB channels of an image, like EmbedDataIntoImage.
+type ImageCarrier struct {
+	Image image.Image
+}
+
+// NewImageCarrier wraps an image as a Carrier.
+func NewImageCarrier(img image.Image) *ImageCarrier {
+	return &ImageCarrier{Image: img}
+}
+
+func (c *ImageCarrier) Capacity(bitDepth uint8) int {
+	if c.Image == nil || bitDepth > MaxBitDepth {
+		return 0
+	}
+
+	return max(c.Image.Bounds().Dx()*c.Image.Bounds().Dy()*3*(int(bitDepth)+1)/8-4, 0)
+}
+
+func (c *ImageCarrier) Embed(data []byte, bitDepth uint8) (Carrier, error) {
+	if c.Image == nil || c.Image.Bounds().Dx() <= 0 || c.Image.Bounds().Dy() <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if bitDepth > MaxBitDepth {
+		return nil, ErrDepthOutOfRange
+	}
+
+	if len(data) > c.Capacity(bitDepth) {
+		return nil, ErrDataTooLarge
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(c.Image, runtime.NumCPU())
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	embeddedRGBChannels, err := u.EmbedIntoRGBchannelsWithDepth(RGBchannels, data, bitDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := u.SaveImage(embeddedRGBChannels, c.Image.Bounds().Dy(), c.Image.Bounds().Dx())
+	if err != nil {
+		return nil, ErrFailedToSaveImage
+	}
+
+	return &ImageCarrier{Image: img}, nil
+}
+
+func (c *ImageCarrier) Extract(bitDepth uint8) ([]byte, error) {
+	if c.Image == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if bitDepth > MaxBitDepth {
+		return nil, ErrDepthOutOfRange
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(c.Image, runtime.NumCPU())
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	data, err := u.ExtractDataFromRGBchannelsWithDepth(RGBchannels, bitDepth)
+	if err != nil {
+		return nil, ErrFailedToExtractData
+	}
+
+	return extractPayload(data, applyOptions(nil))
+}
+
+// AudioCarrier embeds data into the samples of a WAV file, like EmbedIntoWAVWithDepth but without compression.
+type AudioCarrier struct {
+	Buffer     *audio.IntBuffer
+	SampleRate int
+	BitDepth   int
+	NumChans   int
+}
+
+// LoadAudioCarrier reads a WAV file into an AudioCarrier.
+func LoadAudioCarrier(filename string) (*AudioCarrier, error) {
+	decoder := LoadAudioData(filename)
+	if decoder == nil {
+		return nil, ErrInvalidAudio
+	}
+
+	buffer, err := decoder.FullPCMBuffer()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AudioCarrier{
+		Buffer:     buffer,
+		SampleRate: int(decoder.SampleRate),
+		BitDepth:   int(decoder.BitDepth),
+		NumChans:   int(decoder.NumChans),
+	}, nil
+}
+
+// Save writes the carrier to a WAV file.
+func (c *AudioCarrier) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create output file '%s': %v", filename, err)
+	}
+	defer f.Close()
+
+	encoder := wav.NewEncoder(f, c.SampleRate, c.BitDepth, c.NumChans, 1)
+	if err := encoder.Write(c.Buffer); err != nil {
+		return fmt.Errorf("failed to encode WAV file '%s': %v", filename, err)
+	}
+
+	return encoder.Close()
+}
+
+func (c *AudioCarrier) Capacity(bitDepth uint8) int {
+	if c.Buffer == nil || bitDepth > MaxBitDepth {
+		return 0
+	}
+
+	// EmbedDataWithDepthAudio needs a spare sample after the length prefix and data
+	return max(((len(c.Buffer.Data)-1)/(int(bitDepth)+1)-32)/8, 0)
+}
+
+func (c *AudioCarrier) Embed(data []byte, bitDepth uint8) (Carrier, error) {
+	if c.Buffer == nil {
+		return nil, ErrInvalidAudio
+	}
+
+	if bitDepth > MaxBitDepth {
+		return nil, ErrDepthOutOfRange
+	}
+
+	if len(data) > c.Capacity(bitDepth) {
+		return nil, ErrDataTooLarge
+	}
+
+	buffer := &audio.IntBuffer{
+		Format:         c.Buffer.Format,
+		Data:           slices.Clone(c.Buffer.Data),
+		SourceBitDepth: c.Buffer.SourceBitDepth,
+	}
+
+	buffer, err := u.EmbedDataWithDepthAudio(buffer, data, bitDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AudioCarrier{Buffer: buffer, SampleRate: c.SampleRate, BitDepth: c.BitDepth, NumChans: c.NumChans}, nil
+}
+
+func (c *AudioCarrier) Extract(bitDepth uint8) ([]byte, error) {
+	if c.Buffer == nil {
+		return nil, ErrInvalidAudio
+	}
+
+	if bitDepth > MaxBitDepth {
+		return nil, ErrDepthOutOfRange
+	}
+
+	return extractPayload(u.ExtractDataWithDepthAudio(c.Buffer, bitDepth), applyOptions(nil))
+}
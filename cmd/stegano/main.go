@@ -0,0 +1,231 @@
+// Command stegano exposes debugging tools of the stegano library on the command line.
+//
+// Usage:
+//
+//	stegano bitplane -in image.png -channel r -plane 0 -out plane.png
+//	stegano diff -cover cover.png -stego stego.png -out diff.png
+//	stegano list -in stego.png -password secret
+//	stegano extract -in stego.png -password secret -dir out notes.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/scott-mescudi/stegano"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "bitplane", usage: "render one bit-plane of a channel as a black and white image", run: runBitPlane},
+	{name: "diff", usage: "render a heatmap of the differences between a cover and a stego image", run: runDiff},
+	{name: "list", usage: "list the files embedded with EmbedFiles", run: runList},
+	{name: "extract", usage: "extract selected files embedded with EmbedFiles", run: runExtract},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: stegano <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name != os.Args[1] {
+			continue
+		}
+
+		if err := c.run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "stegano %s: %v\n", c.name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func parseChannel(s string) (stegano.Channel, error) {
+	switch strings.ToLower(s) {
+	case "r", "red":
+		return stegano.Red, nil
+	case "g", "green":
+		return stegano.Green, nil
+	case "b", "blue":
+		return stegano.Blue, nil
+	}
+
+	return 0, fmt.Errorf("unknown channel %q, expected r, g or b", s)
+}
+
+func runBitPlane(args []string) error {
+	fs := flag.NewFlagSet("bitplane", flag.ExitOnError)
+	in := fs.String("in", "", "input image (png or jpeg)")
+	channel := fs.String("channel", "r", "channel to render: r, g or b")
+	plane := fs.Uint("plane", 0, "bit-plane to render, 0 is the LSB")
+	out := fs.String("out", "bitplane.png", "output png file")
+	fs.Parse(args)
+
+	if *in == "" {
+		return fmt.Errorf("-in is required")
+	}
+
+	if *plane > uint(stegano.MaxBitDepth) {
+		return stegano.ErrDepthOutOfRange
+	}
+
+	ch, err := parseChannel(*channel)
+	if err != nil {
+		return err
+	}
+
+	img, err := stegano.Decodeimage(*in)
+	if err != nil {
+		return err
+	}
+
+	rendered, err := stegano.RenderBitPlane(img, ch, uint8(*plane))
+	if err != nil {
+		return err
+	}
+
+	return stegano.SaveImage(*out, rendered)
+}
+
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	cover := fs.String("cover", "", "original image (png or jpeg)")
+	stego := fs.String("stego", "", "image with embedded data (png or jpeg)")
+	out := fs.String("out", "diff.png", "output png file")
+	fs.Parse(args)
+
+	if *cover == "" || *stego == "" {
+		return fmt.Errorf("-cover and -stego are required")
+	}
+
+	coverImage, err := stegano.Decodeimage(*cover)
+	if err != nil {
+		return err
+	}
+
+	stegoImage, err := stegano.Decodeimage(*stego)
+	if err != nil {
+		return err
+	}
+
+	heatmap, err := stegano.RenderDiffHeatmap(coverImage, stegoImage)
+	if err != nil {
+		return err
+	}
+
+	return stegano.SaveImage(*out, heatmap)
+}
+
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	in := fs.String("in", "", "image holding the files")
+	password := fs.String("password", "", "password used when embedding")
+	depth := fs.Uint("depth", 0, "bit depth used when embedding")
+	fs.Parse(args)
+
+	if *in == "" || *password == "" {
+		return fmt.Errorf("-in and -password are required")
+	}
+
+	if *depth > uint(stegano.MaxBitDepth) {
+		return stegano.ErrDepthOutOfRange
+	}
+
+	files, err := stegano.ListFiles(*in, *password, uint8(*depth))
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODE\tSIZE\tMODIFIED\tSHA256\tNAME")
+	for _, f := range files {
+		fmt.Fprintf(w, "%v\t%d\t%s\t%x\t%s\n", f.Mode, f.Size, f.ModTime.Format(time.DateTime), f.SHA256[:8], f.Name)
+	}
+
+	return w.Flush()
+}
+
+func parseOverwritePolicy(s string) (stegano.OverwritePolicy, error) {
+	switch s {
+	case "fail":
+		return stegano.OverwriteFail, nil
+	case "rename":
+		return stegano.OverwriteRename, nil
+	case "overwrite":
+		return stegano.OverwriteReplace, nil
+	}
+
+	return 0, fmt.Errorf("unknown overwrite policy %q, expected fail, rename or overwrite", s)
+}
+
+func runExtract(args []string) error {
+	fs := flag.NewFlagSet("extract", flag.ExitOnError)
+	in := fs.String("in", "", "image holding the files")
+	password := fs.String("password", "", "password used when embedding")
+	depth := fs.Uint("depth", 0, "bit depth used when embedding")
+	dir := fs.String("dir", ".", "directory to write the files to")
+	overwrite := fs.String("overwrite", "fail", "what to do with existing files: fail, rename or overwrite")
+	stdout := fs.Bool("stdout", false, "write the contents to stdout instead of creating files")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: stegano extract [flags] [name ...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *in == "" || *password == "" {
+		return fmt.Errorf("-in and -password are required")
+	}
+
+	if *depth > uint(stegano.MaxBitDepth) {
+		return stegano.ErrDepthOutOfRange
+	}
+
+	policy, err := parseOverwritePolicy(*overwrite)
+	if err != nil {
+		return err
+	}
+
+	files, err := stegano.ExtractFiles(*in, *password, uint8(*depth), fs.Args()...)
+	if err != nil {
+		return err
+	}
+
+	if *stdout {
+		for _, f := range files {
+			if _, err := os.Stdout.Write(f.Data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	paths, err := stegano.WriteFiles(files, *dir, policy)
+	for _, path := range paths {
+		fmt.Println(path)
+	}
+
+	return err
+}
@@ -0,0 +1,162 @@
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Framed format: one byte codec ID followed by the output of the codec. Data written by CompressZSTD
+// has no ID byte, it starts with the zstd magic number instead, so the first magic byte is never used as an ID
+// and Decompress still reads it.
+
+// Codec IDs of the built-in codecs.
+const (
+	IDNone byte = iota
+	IDZstd
+	IDGzip
+	IDDeflate
+	IDBrotli
+	IDLZ4
+	IDXZ
+)
+
+var zstdMagic = []byte{0x28, 0xB5, 0x2F, 0xFD}
+
+var (
+	ErrUnknownCodec    = errors.New("unknown compression codec")
+	ErrCodecRegistered = errors.New("codec ID is reserved or already registered")
+	ErrEmptyFrame      = errors.New("compressed data is empty")
+)
+
+// Codec compresses and decompresses data. The ID is stored in front of the compressed data
+// so Decompress can find the codec again, it must be unique among the registered codecs.
+type Codec interface {
+	ID() byte
+	Name() string
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[byte]Codec{}
+)
+
+func init() {
+	for _, codec := range []Codec{None, Zstd, Gzip, Deflate, Brotli, LZ4, XZ, ZstdDict} {
+		if err := Register(codec); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register makes codec available to Decompress and CompressAuto.
+func Register(codec Codec) error {
+	if codec == nil {
+		return fmt.Errorf("codec cannot be nil")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[codec.ID()]; ok || codec.ID() == zstdMagic[0] {
+		return fmt.Errorf("%w: %d", ErrCodecRegistered, codec.ID())
+	}
+
+	registry[codec.ID()] = codec
+	return nil
+}
+
+// Lookup returns the registered codec with the given ID.
+func Lookup(id byte) (Codec, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	codec, ok := registry[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownCodec, id)
+	}
+
+	return codec, nil
+}
+
+// Codecs returns the registered codecs ordered by ID.
+func Codecs() []Codec {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	codecs := make([]Codec, 0, len(registry))
+	for _, codec := range registry {
+		codecs = append(codecs, codec)
+	}
+
+	sort.Slice(codecs, func(i, j int) bool { return codecs[i].ID() < codecs[j].ID() })
+	return codecs
+}
+
+// Compress compresses data with codec and prefixes the result with the codec ID.
+func Compress(codec Codec, data []byte) ([]byte, error) {
+	if codec == nil {
+		return nil, fmt.Errorf("codec cannot be nil")
+	}
+
+	body, err := codec.Compress(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", codec.Name(), err)
+	}
+
+	return append([]byte{codec.ID()}, body...), nil
+}
+
+// CompressAuto compresses data with every candidate codec, all registered codecs if none are given,
+// and returns the smallest framed output. Since None is registered the output is at most one byte
+// larger than data. ZstdDict is skipped while no dictionary is registered.
+func CompressAuto(data []byte, candidates ...Codec) ([]byte, error) {
+	if len(candidates) == 0 {
+		candidates = Codecs()
+	}
+
+	var best []byte
+	for _, codec := range candidates {
+		out, err := Compress(codec, data)
+		if errors.Is(err, ErrNoDictionary) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if best == nil || len(out) < len(best) {
+			best = out
+		}
+	}
+
+	return best, nil
+}
+
+// Decompress reverses Compress and CompressAuto using the codec named by the ID byte.
+// Unframed zstd data as written by CompressZSTD is detected by its magic number and decompressed as well.
+func Decompress(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyFrame
+	}
+
+	if bytes.HasPrefix(data, zstdMagic) {
+		return DecompressZSTD(data)
+	}
+
+	codec, err := Lookup(data[0])
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := codec.Decompress(data[1:])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", codec.Name(), err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,139 @@
+package compression
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestCodecsRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("stegano hides data in plain sight. "), 50)
+
+	codecs := append(Codecs(), ZstdLevel(1), ZstdLevel(19))
+	for _, codec := range codecs {
+		t.Run(codec.Name(), func(t *testing.T) {
+			framed, err := Compress(codec, data)
+			if errors.Is(err, ErrNoDictionary) {
+				t.Skip("no dictionary registered")
+			}
+			if err != nil {
+				t.Fatalf("Compress() error = %v", err)
+			}
+
+			if framed[0] != codec.ID() {
+				t.Fatalf("expected codec ID %d, got %d", codec.ID(), framed[0])
+			}
+
+			got, err := Decompress(framed)
+			if err != nil {
+				t.Fatalf("Decompress() error = %v", err)
+			}
+
+			if !bytes.Equal(got, data) {
+				t.Fatal("decompressed data does not match original")
+			}
+		})
+	}
+}
+
+func TestDecompressLegacyZSTD(t *testing.T) {
+	data := []byte("Hello, World!")
+	compressed, err := CompressZSTD(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Decompress(compressed)
+	if err != nil {
+		t.Fatalf("Decompress() error = %v", err)
+	}
+
+	if !bytes.Equal(got, data) {
+		t.Fatal("decompressed data does not match original")
+	}
+}
+
+func TestCompressAuto(t *testing.T) {
+	random := make([]byte, 1024)
+	rand.Read(random)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "repetitive", data: bytes.Repeat([]byte("abc"), 1000)},
+		{name: "random", data: random},
+		{name: "tiny", data: []byte("hi")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auto, err := CompressAuto(tt.data)
+			if err != nil {
+				t.Fatalf("CompressAuto() error = %v", err)
+			}
+
+			for _, codec := range Codecs() {
+				framed, err := Compress(codec, tt.data)
+				if errors.Is(err, ErrNoDictionary) {
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(framed) < len(auto) {
+					t.Fatalf("%s output is smaller than the auto choice: %d < %d", codec.Name(), len(framed), len(auto))
+				}
+			}
+
+			if len(auto) > len(tt.data)+1 {
+				t.Fatalf("expected at most %d bytes, got %d", len(tt.data)+1, len(auto))
+			}
+
+			got, err := Decompress(auto)
+			if err != nil || !bytes.Equal(got, tt.data) {
+				t.Fatalf("round trip failed: %v", err)
+			}
+		})
+	}
+}
+
+type testCodec struct {
+	id byte
+}
+
+func (c testCodec) ID() byte                               { return c.id }
+func (c testCodec) Name() string                           { return "test" }
+func (c testCodec) Compress(data []byte) ([]byte, error)   { return data, nil }
+func (c testCodec) Decompress(data []byte) ([]byte, error) { return data, nil }
+
+func TestRegister(t *testing.T) {
+	if err := Register(testCodec{id: IDZstd}); !errors.Is(err, ErrCodecRegistered) {
+		t.Fatalf("expected error: %v, got: %v", ErrCodecRegistered, err)
+	}
+
+	if err := Register(testCodec{id: zstdMagic[0]}); !errors.Is(err, ErrCodecRegistered) {
+		t.Fatalf("expected error: %v, got: %v", ErrCodecRegistered, err)
+	}
+
+	if _, err := Decompress([]byte{0xF0, 1, 2, 3}); !errors.Is(err, ErrUnknownCodec) {
+		t.Fatalf("expected error: %v, got: %v", ErrUnknownCodec, err)
+	}
+
+	if err := Register(testCodec{id: 0xF0}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	got, err := Decompress([]byte{0xF0, 1, 2, 3})
+	if err != nil || !bytes.Equal(got, []byte{1, 2, 3}) {
+		t.Fatalf("expected registered codec to be used, got %v, %v", got, err)
+	}
+}
+
+func TestInvalidZstdLevel(t *testing.T) {
+	if _, err := Compress(ZstdLevel(0), []byte("data")); err == nil {
+		t.Fatal("expected an error for zstd level 0")
+	}
+}
@@ -0,0 +1,151 @@
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+)
+
+// DefaultZstdLevel is the zstd level used by Zstd, matching CompressZSTD.
+const DefaultZstdLevel = 3
+
+// Built-in codecs, registered under the IDs above.
+var (
+	None    Codec = noneCodec{}
+	Zstd    Codec = ZstdLevel(DefaultZstdLevel)
+	Gzip    Codec = streamCodec{id: IDGzip, name: "gzip", newWriter: newGzipWriter, newReader: newGzipReader}
+	Deflate Codec = streamCodec{id: IDDeflate, name: "deflate", newWriter: newDeflateWriter, newReader: newDeflateReader}
+	Brotli  Codec = streamCodec{id: IDBrotli, name: "brotli", newWriter: newBrotliWriter, newReader: newBrotliReader}
+	LZ4     Codec = streamCodec{id: IDLZ4, name: "lz4", newWriter: newLZ4Writer, newReader: newLZ4Reader}
+	XZ      Codec = streamCodec{id: IDXZ, name: "xz", newWriter: newXZWriter, newReader: newXZReader}
+)
+
+type noneCodec struct{}
+
+func (noneCodec) ID() byte                               { return IDNone }
+func (noneCodec) Name() string                           { return "none" }
+func (noneCodec) Compress(data []byte) ([]byte, error)   { return bytes.Clone(data), nil }
+func (noneCodec) Decompress(data []byte) ([]byte, error) { return bytes.Clone(data), nil }
+
+type zstdCodec struct {
+	level int
+}
+
+// ZstdLevel returns a zstd codec compressing at level, from 1 (fastest) to 22 (smallest), mapped onto the
+// encoder speeds of klauspost/compress. The level is only used when compressing, all levels share IDZstd.
+func ZstdLevel(level int) Codec {
+	return zstdCodec{level: level}
+}
+
+func (z zstdCodec) ID() byte     { return IDZstd }
+func (z zstdCodec) Name() string { return fmt.Sprintf("zstd-%d", z.level) }
+
+func (z zstdCodec) Compress(data []byte) ([]byte, error) {
+	if z.level < 1 || z.level > 22 {
+		return nil, fmt.Errorf("zstd level must be between 1 and 22, got %d", z.level)
+	}
+
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(z.level)))
+	if err != nil {
+		return nil, err
+	}
+	defer encoder.Close()
+
+	return encoder.EncodeAll(data, nil), nil
+}
+
+func (z zstdCodec) Decompress(data []byte) ([]byte, error) {
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer decoder.Close()
+
+	return decoder.DecodeAll(data, nil)
+}
+
+// streamCodec adapts the io.Writer and io.Reader based compressors.
+type streamCodec struct {
+	id        byte
+	name      string
+	newWriter func(w io.Writer) (io.WriteCloser, error)
+	newReader func(r io.Reader) (io.Reader, error)
+}
+
+func (s streamCodec) ID() byte     { return s.id }
+func (s streamCodec) Name() string { return s.name }
+
+func (s streamCodec) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := s.newWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s streamCodec) Decompress(data []byte) ([]byte, error) {
+	r, err := s.newReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+func newGzipWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, gzip.BestCompression)
+}
+
+func newGzipReader(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+func newDeflateWriter(w io.Writer) (io.WriteCloser, error) {
+	return flate.NewWriter(w, flate.BestCompression)
+}
+
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	return flate.NewReader(r), nil
+}
+
+func newBrotliWriter(w io.Writer) (io.WriteCloser, error) {
+	return brotli.NewWriterLevel(w, brotli.BestCompression), nil
+}
+
+func newBrotliReader(r io.Reader) (io.Reader, error) {
+	return brotli.NewReader(r), nil
+}
+
+func newLZ4Writer(w io.Writer) (io.WriteCloser, error) {
+	return lz4.NewWriter(w), nil
+}
+
+func newLZ4Reader(r io.Reader) (io.Reader, error) {
+	return lz4.NewReader(r), nil
+}
+
+func newXZWriter(w io.Writer) (io.WriteCloser, error) {
+	return xz.NewWriter(w)
+}
+
+func newXZReader(r io.Reader) (io.Reader, error) {
+	return xz.NewReader(r)
+}
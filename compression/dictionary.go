@@ -0,0 +1,261 @@
+package compression
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Dictionary frames use IDZstdDict and hold the dictionary ID as an uvarint followed by a zstd frame
+// compressed with that dictionary. Decompress looks the dictionary up in the registered ones.
+
+// IDZstdDict is the codec ID of zstd frames compressed with a registered dictionary.
+const IDZstdDict byte = IDXZ + 1
+
+// DefaultDictionarySize is the dictionary size used by TrainZstdDictionary when none is given.
+const DefaultDictionarySize = 16 << 10
+
+const (
+	dictGramSize    = 8
+	dictSegmentSize = 32
+)
+
+var (
+	ErrNoDictionary         = errors.New("no zstd dictionary is registered")
+	ErrUnknownDictionary    = errors.New("zstd dictionary is not registered")
+	ErrDictionaryRegistered = errors.New("a different zstd dictionary is registered with this ID")
+)
+
+// ZstdDict compresses with whichever registered dictionary gives the smallest output.
+var ZstdDict Codec = zstdDictCodec{level: DefaultZstdLevel}
+
+var (
+	dictMu       sync.RWMutex
+	dictionaries = map[uint32][]byte{}
+)
+
+// TrainZstdDictionary builds a zstd dictionary with the given ID from samples of the data that will be compressed.
+// The content is made of the sample segments sharing the most substrings with other samples, up to maxSize bytes,
+// DefaultDictionarySize if maxSize is not positive. Dictionaries help most with many small, similar payloads.
+func TrainZstdDictionary(samples [][]byte, id uint32, maxSize int) ([]byte, error) {
+	if id == 0 {
+		return nil, fmt.Errorf("dictionary ID cannot be 0")
+	}
+
+	if maxSize <= 0 {
+		maxSize = DefaultDictionarySize
+	}
+
+	history := dictionaryContent(samples, maxSize)
+	if len(history) < dictGramSize {
+		return nil, fmt.Errorf("samples hold too little repeated content to train a dictionary")
+	}
+
+	return zstd.BuildDict(zstd.BuildDictOptions{
+		ID:       id,
+		Contents: samples,
+		History:  history,
+		Offsets:  [3]int{1, 4, 8},
+		Level:    zstd.EncoderLevelFromZstd(DefaultZstdLevel),
+	})
+}
+
+// dictionaryContent greedily picks the segments whose substrings occur in the most samples,
+// skipping substrings already covered, and places the best segments last where zstd finds them cheapest.
+func dictionaryContent(samples [][]byte, maxSize int) []byte {
+	freq := make(map[string]int)
+	for _, sample := range samples {
+		seen := make(map[string]bool)
+		for i := 0; i+dictGramSize <= len(sample); i++ {
+			gram := string(sample[i : i+dictGramSize])
+			if !seen[gram] {
+				seen[gram] = true
+				freq[gram]++
+			}
+		}
+	}
+
+	score := func(segment []byte) int {
+		total := 0
+		for i := 0; i+dictGramSize <= len(segment); i++ {
+			if n := freq[string(segment[i:i+dictGramSize])]; n > 1 {
+				total += n
+			}
+		}
+		return total
+	}
+
+	type candidate struct {
+		data  []byte
+		score int
+	}
+
+	var candidates []candidate
+	for _, sample := range samples {
+		for start := 0; start < len(sample); start += dictSegmentSize {
+			// overlap the next segment so substrings crossing the boundary are kept
+			end := min(start+dictSegmentSize+dictGramSize-1, len(sample))
+			if s := score(sample[start:end]); s > 0 {
+				candidates = append(candidates, candidate{data: sample[start:end], score: s})
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	var picked [][]byte
+	size := 0
+	for _, cand := range candidates {
+		if size+len(cand.data) > maxSize {
+			break
+		}
+
+		if score(cand.data) == 0 {
+			continue
+		}
+
+		picked = append(picked, cand.data)
+		size += len(cand.data)
+		for i := 0; i+dictGramSize <= len(cand.data); i++ {
+			delete(freq, string(cand.data[i:i+dictGramSize]))
+		}
+	}
+
+	history := make([]byte, 0, size)
+	for i := len(picked) - 1; i >= 0; i-- {
+		history = append(history, picked[i]...)
+	}
+
+	return history
+}
+
+// RegisterZstdDictionary makes a dictionary made by TrainZstdDictionary, or the zstd command line tool,
+// available for compression and decompression and returns its ID.
+func RegisterZstdDictionary(dict []byte) (uint32, error) {
+	info, err := zstd.InspectDictionary(dict)
+	if err != nil {
+		return 0, err
+	}
+
+	id := info.ID()
+	if id == 0 {
+		return 0, fmt.Errorf("dictionary ID cannot be 0")
+	}
+
+	dictMu.Lock()
+	defer dictMu.Unlock()
+
+	if existing, ok := dictionaries[id]; ok {
+		if !bytes.Equal(existing, dict) {
+			return 0, fmt.Errorf("%w: %d", ErrDictionaryRegistered, id)
+		}
+		return id, nil
+	}
+
+	dictionaries[id] = bytes.Clone(dict)
+	return id, nil
+}
+
+func lookupDictionary(id uint32) ([]byte, error) {
+	dictMu.RLock()
+	defer dictMu.RUnlock()
+
+	dict, ok := dictionaries[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownDictionary, id)
+	}
+
+	return dict, nil
+}
+
+func dictionaryIDs() []uint32 {
+	dictMu.RLock()
+	defer dictMu.RUnlock()
+
+	ids := make([]uint32, 0, len(dictionaries))
+	for id := range dictionaries {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+type zstdDictCodec struct {
+	id    uint32
+	level int
+}
+
+// ZstdDictionary returns a codec compressing with the registered dictionary id at the given zstd level.
+func ZstdDictionary(id uint32, level int) Codec {
+	return zstdDictCodec{id: id, level: level}
+}
+
+func (z zstdDictCodec) ID() byte { return IDZstdDict }
+
+func (z zstdDictCodec) Name() string {
+	if z.id == 0 {
+		return fmt.Sprintf("zstd-dict-%d", z.level)
+	}
+	return fmt.Sprintf("zstd-dict-%d-%d", z.id, z.level)
+}
+
+func (z zstdDictCodec) Compress(data []byte) ([]byte, error) {
+	if z.level < 1 || z.level > 22 {
+		return nil, fmt.Errorf("zstd level must be between 1 and 22, got %d", z.level)
+	}
+
+	ids := []uint32{z.id}
+	if z.id == 0 {
+		if ids = dictionaryIDs(); len(ids) == 0 {
+			return nil, ErrNoDictionary
+		}
+	}
+
+	var best []byte
+	for _, id := range ids {
+		dict, err := lookupDictionary(id)
+		if err != nil {
+			return nil, err
+		}
+
+		encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(z.level)), zstd.WithEncoderDict(dict))
+		if err != nil {
+			return nil, err
+		}
+
+		out := encoder.EncodeAll(data, binary.AppendUvarint(nil, uint64(id)))
+		encoder.Close()
+
+		if best == nil || len(out) < len(best) {
+			best = out
+		}
+	}
+
+	return best, nil
+}
+
+func (z zstdDictCodec) Decompress(data []byte) ([]byte, error) {
+	id, n := binary.Uvarint(data)
+	if n <= 0 || id > 0xFFFFFFFF {
+		return nil, fmt.Errorf("invalid dictionary ID")
+	}
+
+	dict, err := lookupDictionary(uint32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderDicts(dict))
+	if err != nil {
+		return nil, err
+	}
+	defer decoder.Close()
+
+	return decoder.DecodeAll(data[n:], nil)
+}
@@ -0,0 +1,111 @@
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func jsonSamples(n int) [][]byte {
+	samples := make([][]byte, n)
+	for i := range samples {
+		samples[i] = []byte(fmt.Sprintf(`{"type":"telemetry","device":"sensor-%03d","status":"ok","temperature":%d,"humidity":%d}`, i%50, 15+i%20, 40+i%30))
+	}
+
+	return samples
+}
+
+func TestZstdDictionary(t *testing.T) {
+	samples := jsonSamples(500)
+	dict, err := TrainZstdDictionary(samples, 1001, 4<<10)
+	if err != nil {
+		t.Fatalf("TrainZstdDictionary() error = %v", err)
+	}
+
+	id, err := RegisterZstdDictionary(dict)
+	if err != nil {
+		t.Fatalf("RegisterZstdDictionary() error = %v", err)
+	}
+
+	if id != 1001 {
+		t.Fatalf("expected dictionary ID 1001, got %d", id)
+	}
+
+	// registering the same dictionary again is fine
+	if _, err := RegisterZstdDictionary(dict); err != nil {
+		t.Fatalf("RegisterZstdDictionary() error = %v", err)
+	}
+
+	message := []byte(`{"type":"telemetry","device":"sensor-777","status":"ok","temperature":21,"humidity":55}`)
+
+	plain, err := Compress(Zstd, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, codec := range []Codec{ZstdDictionary(id, DefaultZstdLevel), ZstdDict} {
+		framed, err := Compress(codec, message)
+		if err != nil {
+			t.Fatalf("%s: Compress() error = %v", codec.Name(), err)
+		}
+
+		if len(framed) >= len(plain) || len(framed) >= len(message) {
+			t.Fatalf("%s: expected dictionary to shrink the message, got %d bytes, %d without dictionary, %d raw", codec.Name(), len(framed), len(plain), len(message))
+		}
+
+		got, err := Decompress(framed)
+		if err != nil {
+			t.Fatalf("%s: Decompress() error = %v", codec.Name(), err)
+		}
+
+		if !bytes.Equal(got, message) {
+			t.Fatalf("%s: decompressed data does not match original", codec.Name())
+		}
+	}
+
+	auto, err := CompressAuto(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if auto[0] != IDZstdDict {
+		t.Fatalf("expected auto to pick the dictionary, got codec %d", auto[0])
+	}
+}
+
+func TestZstdDictionary_Errors(t *testing.T) {
+	if _, err := TrainZstdDictionary(jsonSamples(10), 0, 0); err == nil {
+		t.Fatal("expected an error for dictionary ID 0")
+	}
+
+	if _, err := TrainZstdDictionary([][]byte{[]byte("a"), []byte("b")}, 7, 0); err == nil {
+		t.Fatal("expected an error for samples without repeated content")
+	}
+
+	if _, err := Compress(ZstdDictionary(4242, DefaultZstdLevel), []byte("data")); !errors.Is(err, ErrUnknownDictionary) {
+		t.Fatalf("expected error: %v, got: %v", ErrUnknownDictionary, err)
+	}
+
+	if _, err := Decompress([]byte{IDZstdDict, 0x92, 0x21, 0x28, 0xB5}); !errors.Is(err, ErrUnknownDictionary) {
+		t.Fatalf("expected error: %v, got: %v", ErrUnknownDictionary, err)
+	}
+
+	first, err := TrainZstdDictionary(jsonSamples(100), 2002, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := TrainZstdDictionary([][]byte{[]byte("another sample text"), []byte("another sample text!")}, 2002, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := RegisterZstdDictionary(first); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := RegisterZstdDictionary(second); !errors.Is(err, ErrDictionaryRegistered) {
+		t.Fatalf("expected error: %v, got: %v", ErrDictionaryRegistered, err)
+	}
+}
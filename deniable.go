@@ -0,0 +1,169 @@
+package stegano
+
+import (
+	"crypto/rand"
+	"image"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// deniableSlots splits the capacity of the RGB channels at the given bit depth into two equally sized slots.
+func deniableSlots(RGBchannels []u.RgbChannel, bitDepth uint8) (slotSize, totalSize int) {
+	bits := len(RGBchannels) * 3 * (int(bitDepth) + 1)
+	return (bits / 8) / 2, (bits + 7) / 8
+}
+
+// EncodeDeniable embeds a decoy payload and an optional hidden payload into the cover image, each sealed under its own password.
+// The usable capacity at the given bit depth is split into two slots which are completely filled with ciphertext or random bytes,
+// so an image holding only a decoy looks exactly like one holding a decoy and a hidden payload.
+// The slot used by each payload is chosen at random.
+//
+// Parameters:
+// - coverImage: The image to embed data into.
+// - decoy: The payload revealed when the decoy password is used.
+// - decoyPassword: The password sealing the decoy payload.
+// - hidden: The real payload. May be nil, in which case its slot is filled with random bytes.
+// - hiddenPassword: The password sealing the hidden payload. Ignored when hidden is nil.
+// - bitDepth: The bit depth used for embedding (valid range: 0-7).
+// - outputFilename: The file name to save the resulting image. Defaults to a pre-defined name if empty.
+func (m *SecureEmbedHandler) EncodeDeniable(coverImage image.Image, decoy []byte, decoyPassword string, hidden []byte, hiddenPassword string, bitDepth uint8, outputFilename string) error {
+	if coverImage == nil {
+		return ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return ErrInvalidCoverImage
+	}
+
+	if bitDepth > 7 {
+		return ErrDepthOutOfRange
+	}
+
+	if len(decoy) == 0 {
+		return ErrInvalidData
+	}
+
+	if decoyPassword == "" || (hidden != nil && hiddenPassword == "") {
+		return ErrInvalidPassword
+	}
+
+	if hidden != nil && hiddenPassword == decoyPassword {
+		return ErrSamePassword
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return ErrFailedToExtractRGB
+	}
+
+	slotSize, totalSize := deniableSlots(RGBchannels, bitDepth)
+	if len(decoy)+u.SlotOverhead > slotSize || len(hidden)+u.SlotOverhead > slotSize {
+		return ErrDataTooLarge
+	}
+
+	decoySlot, err := u.SealSlot(decoyPassword, decoy, slotSize)
+	if err != nil {
+		return err
+	}
+
+	var hiddenSlot []byte
+	if hidden != nil {
+		hiddenSlot, err = u.SealSlot(hiddenPassword, hidden, slotSize)
+	} else {
+		hiddenSlot, err = u.RandomSlot(slotSize)
+	}
+	if err != nil {
+		return err
+	}
+
+	var order [1]byte
+	if _, err := rand.Read(order[:]); err != nil {
+		return err
+	}
+
+	if order[0]&1 == 1 {
+		decoySlot, hiddenSlot = hiddenSlot, decoySlot
+	}
+
+	tail, err := u.RandomSlot(totalSize - 2*slotSize)
+	if err != nil {
+		return err
+	}
+
+	stream := make([]byte, 0, totalSize)
+	stream = append(stream, decoySlot...)
+	stream = append(stream, hiddenSlot...)
+	stream = append(stream, tail...)
+
+	embeddedRGBChannels, err := u.EmbedRawIntoRGBchannelsWithDepth(RGBchannels, stream, bitDepth)
+	if err != nil {
+		return err
+	}
+
+	imgdata, err := u.SaveImage(embeddedRGBChannels, height, width)
+	if err != nil {
+		return ErrFailedToSaveImage
+	}
+
+	if outputFilename == "" {
+		outputFilename = DefaultOutputFile
+	}
+
+	return SaveImage(outputFilename, imgdata)
+}
+
+// DecodeDeniable extracts the payload sealed under the given password from an image created by EncodeDeniable.
+// Both slots are tried, so the caller does not need to know which payload the password belongs to.
+// Returns ErrNoPayloadForPassword if neither slot can be opened.
+//
+// Parameters:
+// - coverImage: The image containing the embedded slots.
+// - bitDepth: The bit depth used during embedding (valid range: 0-7).
+// - password: The password of the decoy or the hidden payload.
+func (m *SecureExtractHandler) DecodeDeniable(coverImage image.Image, bitDepth uint8, password string) ([]byte, error) {
+	if coverImage == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if bitDepth > 7 {
+		return nil, ErrDepthOutOfRange
+	}
+
+	if password == "" {
+		return nil, ErrInvalidPassword
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	data, err := u.ExtractDataFromRGBchannelsWithDepth(RGBchannels, bitDepth)
+	if err != nil {
+		return nil, ErrFailedToExtractData
+	}
+
+	slotSize, _ := deniableSlots(RGBchannels, bitDepth)
+	if slotSize < u.SlotOverhead || len(data) < 2*slotSize {
+		return nil, ErrNoPayloadForPassword
+	}
+
+	for i := 0; i < 2; i++ {
+		payload, err := u.OpenSlot(password, data[i*slotSize:(i+1)*slotSize])
+		if err == nil {
+			return payload, nil
+		}
+	}
+
+	return nil, ErrNoPayloadForPassword
+}
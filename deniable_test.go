@@ -0,0 +1,52 @@
+package stegano
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestEncodeDecodeDeniable(t *testing.T) {
+	outputFilename := "test_deniable_output.png"
+	defer os.Remove(outputFilename)
+
+	embedder := NewSecureEmbedHandler()
+	err := embedder.EncodeDeniable(createTestImage(), []byte("shopping list"), "decoy", []byte("real message"), "real", LSB, outputFilename)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	img, err := Decodeimage(outputFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extractor := NewSecureExtractHandler()
+	tests := map[string]string{
+		"decoy": "shopping list",
+		"real":  "real message",
+	}
+
+	for password, expected := range tests {
+		data, err := extractor.DecodeDeniable(img, LSB, password)
+		if err != nil {
+			t.Fatalf("expected no error for password %q, got: %v", password, err)
+		}
+
+		if string(data) != expected {
+			t.Fatalf("expected %q, got %q", expected, data)
+		}
+	}
+
+	if _, err := extractor.DecodeDeniable(img, LSB, "unknown"); !errors.Is(err, ErrNoPayloadForPassword) {
+		t.Fatalf("expected error: %v, got: %v", ErrNoPayloadForPassword, err)
+	}
+}
+
+func TestEncodeDeniable_SamePassword(t *testing.T) {
+	embedder := NewSecureEmbedHandler()
+	err := embedder.EncodeDeniable(createTestImage(), []byte("a"), "pw", []byte("b"), "pw", LSB, "test_deniable_output.png")
+	if !errors.Is(err, ErrSamePassword) {
+		t.Fatalf("expected error: %v, got: %v", ErrSamePassword, err)
+	}
+}
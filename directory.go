@@ -0,0 +1,217 @@
+package stegano
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+func validatePatterns(groups ...[]string) error {
+	for _, patterns := range groups {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchAny reports whether one of patterns matches the slash separated name or its last element.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// excluded reports whether name or one of its parent directories matches an exclude pattern.
+func excluded(exclude []string, name string) bool {
+	for ; name != "." && name != "/"; name = path.Dir(name) {
+		if matchAny(exclude, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filterEntries drops the entries matched by exclude and, if include is not empty, the files it does not match
+// and the directories left without any file.
+func filterEntries(entries []u.ArchiveEntry, include, exclude []string) []u.ArchiveEntry {
+	keep := make([]bool, len(entries))
+	needed := make(map[string]bool)
+	for i, e := range entries {
+		if e.Mode.IsDir() || excluded(exclude, e.Name) {
+			continue
+		}
+
+		if len(include) == 0 || matchAny(include, e.Name) {
+			keep[i] = true
+			for dir := path.Dir(e.Name); dir != "."; dir = path.Dir(dir) {
+				needed[dir] = true
+			}
+		}
+	}
+
+	var out []u.ArchiveEntry
+	for i, e := range entries {
+		if e.Mode.IsDir() {
+			keep[i] = !excluded(exclude, e.Name) && (len(include) == 0 || needed[e.Name])
+		}
+
+		if keep[i] {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+// walkDirectory reads the directories and regular files below root into archive entries named by their
+// slash separated path relative to root. Other file types such as symlinks are skipped.
+func walkDirectory(root string, exclude []string) ([]u.ArchiveEntry, error) {
+	var entries []u.ArchiveEntry
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		if rel == "." {
+			return nil
+		}
+
+		name := filepath.ToSlash(rel)
+		if matchAny(exclude, name) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		if !d.IsDir() {
+			if data, err = os.ReadFile(p); err != nil {
+				return err
+			}
+		}
+
+		entries = append(entries, u.NewArchiveEntry(name, info.Mode()&(fs.ModeDir|fs.ModePerm), info.ModTime(), data))
+		return nil
+	})
+
+	return entries, err
+}
+
+// EmbedDirectory embeds the directory tree below dirPath into an image as a single compressed and encrypted
+// archive, see EmbedFiles. Files and directories are stored under their slash separated path relative to dirPath,
+// so ExtractDirectory can restore the structure, including empty directories.
+// WithInclude and WithExclude select what is embedded. Glob patterns use the syntax of path.Match and are matched
+// against both the relative path and the base name, an excluded directory excludes everything below it.
+// The capacity of the cover is read from the image header, so a tree that does not fit fails before the image is decoded.
+//
+// Parameters:
+// - coverImagePath: The file path of the image to embed the directory into.
+// - dirPath: The directory to embed.
+// - outputFilePath: The file path to save the resulting image with embedded data.
+// - password: A password used to encrypt the archive before embedding.
+// - bitDepth: The bit depth used for embedding (valid range: 0-7).
+// - opts: Optional settings such as WithInclude, WithExclude or WithCodec.
+func EmbedDirectory(coverImagePath, dirPath, outputFilePath, password string, bitDepth uint8, opts ...Option) error {
+	if dirPath == "" {
+		return errors.New("invalid dirPath")
+	}
+
+	o := applyOptions(opts)
+	if err := validatePatterns(o.include, o.exclude); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(dirPath)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("'%s' is not a directory", dirPath)
+	}
+
+	entries, err := walkDirectory(dirPath, o.exclude)
+	if err != nil {
+		return err
+	}
+
+	entries = filterEntries(entries, o.include, o.exclude)
+	if !hasFiles(entries) {
+		return ErrEmptyDirectory
+	}
+
+	return embedArchive(coverImagePath, entries, outputFilePath, password, bitDepth, o)
+}
+
+// ExtractDirectory restores a tree embedded with EmbedDirectory below outputDir and returns the paths written.
+// WithInclude and WithExclude select what is restored, matching like EmbedDirectory. Names that would escape
+// outputDir are rejected and existing files are handled according to WithOverwritePolicy, see WriteFiles.
+//
+// Parameters:
+// - coverImagePath: The file path of the image containing the directory.
+// - outputDir: The directory to restore the tree into, created if missing.
+// - password: The password used when embedding.
+// - bitDepth: The bit depth used when embedding.
+// - opts: Optional settings such as WithInclude, WithExclude or WithOverwritePolicy.
+func ExtractDirectory(coverImagePath, outputDir, password string, bitDepth uint8, opts ...Option) ([]string, error) {
+	o := applyOptions(opts)
+	if err := validatePatterns(o.include, o.exclude); err != nil {
+		return nil, err
+	}
+
+	entries, err := readArchive(coverImagePath, password, bitDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	entries = filterEntries(entries, o.include, o.exclude)
+	files := make([]File, len(entries))
+	for i, e := range entries {
+		files[i] = File{FileInfo: fileInfoFromEntry(e), Data: e.Data}
+	}
+
+	return WriteFiles(files, outputDir, o.overwrite)
+}
+
+func hasFiles(entries []u.ArchiveEntry) bool {
+	for _, e := range entries {
+		if !e.Mode.IsDir() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,130 @@
+package stegano
+
+import (
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestTree(t *testing.T) string {
+	t.Helper()
+
+	root := filepath.Join(t.TempDir(), "notes")
+	files := map[string]string{
+		"a.md":        "# first note",
+		"sub/b.txt":   "second note",
+		"sub/c.md":    "# third note",
+		".git/config": "[core]",
+		"tmp.log":     "noise",
+	}
+
+	for name, contents := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o640); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.Mkdir(filepath.Join(root, "empty"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	return root
+}
+
+func TestEmbedDirectoryExtractDirectory(t *testing.T) {
+	root := writeTestTree(t)
+	dir := t.TempDir()
+	cover := saveTestCover(t, dir)
+	output := filepath.Join(dir, "out.png")
+
+	if err := EmbedDirectory(cover, root, output, "password", 0, WithExclude(".git", "*.log")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	outputDir := filepath.Join(dir, "restored")
+	if _, err := ExtractDirectory(output, outputDir, "password", 0); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	for name, want := range map[string]string{"a.md": "# first note", "sub/b.txt": "second note", "sub/c.md": "# third note"} {
+		data, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("expected %s to be restored, got: %v", name, err)
+		}
+
+		if string(data) != want {
+			t.Fatalf("%s: expected %q, got %q", name, want, data)
+		}
+	}
+
+	if info, err := os.Stat(filepath.Join(outputDir, "empty")); err != nil || !info.IsDir() {
+		t.Fatalf("expected empty directory to be restored, got: %v", err)
+	}
+
+	for _, name := range []string{".git", "tmp.log"} {
+		if _, err := os.Lstat(filepath.Join(outputDir, name)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be excluded, got: %v", name, err)
+		}
+	}
+
+	if _, err := ExtractDirectory(output, outputDir, "password", 0); !errors.Is(err, ErrFileExists) {
+		t.Fatalf("expected error: %v, got: %v", ErrFileExists, err)
+	}
+}
+
+func TestExtractDirectory_Include(t *testing.T) {
+	root := writeTestTree(t)
+	dir := t.TempDir()
+	cover := saveTestCover(t, dir)
+	output := filepath.Join(dir, "out.png")
+
+	if err := EmbedDirectory(cover, root, output, "password", 0); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	outputDir := filepath.Join(dir, "restored")
+	paths, err := ExtractDirectory(output, outputDir, "password", 0, WithInclude("*.md"), WithExclude("sub"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(paths) != 1 || paths[0] != filepath.Join(outputDir, "a.md") {
+		t.Fatalf("expected only a.md to be restored, got: %v", paths)
+	}
+}
+
+func TestEmbedDirectory_Errors(t *testing.T) {
+	root := writeTestTree(t)
+	dir := t.TempDir()
+	cover := saveTestCover(t, dir)
+	output := filepath.Join(dir, "out.png")
+
+	if err := EmbedDirectory(cover, root, output, "password", 0, WithInclude("*.none")); !errors.Is(err, ErrEmptyDirectory) {
+		t.Fatalf("expected error: %v, got: %v", ErrEmptyDirectory, err)
+	}
+
+	if err := EmbedDirectory(cover, root, output, "password", 0, WithExclude("[")); err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+
+	if err := EmbedDirectory(cover, filepath.Join(root, "a.md"), output, "password", 0); err == nil {
+		t.Fatal("expected an error for a regular file")
+	}
+
+	// random data does not compress, 8KB does not fit into 100x100 pixels at the LSB
+	big := make([]byte, 8<<10)
+	rand.Read(big)
+	if err := os.WriteFile(filepath.Join(root, "big.bin"), big, 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := EmbedDirectory(cover, root, output, "password", 0); !errors.Is(err, ErrDataTooLarge) {
+		t.Fatalf("expected error: %v, got: %v", ErrDataTooLarge, err)
+	}
+}
@@ -0,0 +1,145 @@
+package stegano
+
+import (
+	"image"
+	"sort"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// Suspect is a recipient ranked by TraceFingerprint.
+type Suspect struct {
+	RecipientID string
+	// Score is the Tardos accusation score, innocent recipients score around zero.
+	Score float64
+}
+
+// Fingerprint produces one copy of the cover image per recipient, each carrying a distinct collusion-resistant
+// Tardos codeword as a robust watermark. The codewords are derived from the key and the recipient IDs,
+// so only the key and the list of recipients are needed to trace a leak later.
+//
+// Parameters:
+// - coverImage: The image to distribute.
+// - key: The secret key deriving the code.
+// - recipients: The unique IDs of the recipients, the returned images are in the same order.
+// - codeLength: The number of code bits, longer codes resist larger collusions. Limited by GetWatermarkCapacity * 8.
+// - strength: The watermark strength, see EmbedRobustWatermark.
+func (m *EmbedHandler) Fingerprint(coverImage image.Image, key []byte, recipients []string, codeLength int, strength float64) ([]image.Image, error) {
+	if coverImage == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if len(key) == 0 {
+		return nil, ErrInvalidKey
+	}
+
+	if len(recipients) == 0 || codeLength <= 0 {
+		return nil, ErrInvalidData
+	}
+
+	if codeLength > u.WatermarkBlocks(width, height) {
+		return nil, ErrDataTooLarge
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	biases := u.TardosBiases(key, codeLength)
+	images := make([]image.Image, len(recipients))
+	for i, id := range recipients {
+		channels := make([]u.RgbChannel, len(RGBchannels))
+		copy(channels, RGBchannels)
+
+		watermarked, err := u.EmbedRobustWatermark(channels, width, height, u.TardosCodeword(key, id, biases), strength)
+		if err != nil {
+			return nil, err
+		}
+
+		images[i], err = u.SaveImage(watermarked, height, width)
+		if err != nil {
+			return nil, ErrFailedToSaveImage
+		}
+	}
+
+	return images, nil
+}
+
+// TraceFingerprint detects the Tardos codeword in a leaked image, which may be a single recipient's copy
+// or a mix of several, and ranks the recipients from most to least likely source.
+//
+// Parameters:
+// - leaked: The leaked image.
+// - key: The key used on fingerprinting.
+// - recipients: The candidate recipient IDs.
+// - codeLength: The code length used on fingerprinting.
+// - strength: The watermark strength used on fingerprinting.
+// - opts: Optional settings such as WithOriginalSize for rescaled leaks.
+func (m *ExtractHandler) TraceFingerprint(leaked image.Image, key []byte, recipients []string, codeLength int, strength float64, opts ...Option) ([]Suspect, error) {
+	if leaked == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	height := leaked.Bounds().Dy()
+	width := leaked.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if len(key) == 0 {
+		return nil, ErrInvalidKey
+	}
+
+	if len(recipients) == 0 || codeLength <= 0 {
+		return nil, ErrInvalidData
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(leaked, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	o := applyOptions(opts)
+	if o.originalWidth > 0 && o.originalHeight > 0 && (o.originalWidth != width || o.originalHeight != height) {
+		resized, err := u.ResizeRGBchannels(RGBchannels, width, height, o.originalWidth, o.originalHeight)
+		if err != nil {
+			return nil, err
+		}
+		RGBchannels, width, height = resized, o.originalWidth, o.originalHeight
+	}
+
+	bits, _, err := u.DetectRobustWatermark(RGBchannels, width, height, codeLength, strength)
+	if err != nil {
+		return nil, err
+	}
+
+	biases := u.TardosBiases(key, codeLength)
+	suspects := make([]Suspect, len(recipients))
+	for i, id := range recipients {
+		suspects[i] = Suspect{
+			RecipientID: id,
+			Score:       u.TardosScore(u.TardosCodeword(key, id, biases), biases, bits),
+		}
+	}
+
+	sort.SliceStable(suspects, func(i, j int) bool {
+		return suspects[i].Score > suspects[j].Score
+	})
+
+	return suspects, nil
+}
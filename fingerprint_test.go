@@ -0,0 +1,52 @@
+package stegano
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFingerprint_Trace(t *testing.T) {
+	key := []byte("distribution key")
+	recipients := []string{"alice", "bob", "carol", "dave", "erin", "frank"}
+	codeLength := 256
+
+	copies, err := NewEmbedHandler().Fingerprint(createGradientImage(256, 256), key, recipients, codeLength, DefaultWatermarkStrength)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(copies) != len(recipients) {
+		t.Fatalf("expected %d copies, got %d", len(recipients), len(copies))
+	}
+
+	extractor := NewExtractHandler()
+	suspects, err := extractor.TraceFingerprint(copies[2], key, recipients, codeLength, DefaultWatermarkStrength)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if suspects[0].RecipientID != "carol" {
+		t.Fatalf("expected carol to be the top suspect, got %v", suspects)
+	}
+
+	// bob and erin average their copies to hide the source
+	mixed := image.NewRGBA(copies[1].Bounds())
+	for y := 0; y < 256; y++ {
+		for x := 0; x < 256; x++ {
+			r1, g1, b1, _ := copies[1].At(x, y).RGBA()
+			r2, g2, b2, _ := copies[4].At(x, y).RGBA()
+			mixed.Set(x, y, color.RGBA{R: uint8((r1 + r2) >> 9), G: uint8((g1 + g2) >> 9), B: uint8((b1 + b2) >> 9), A: 255})
+		}
+	}
+
+	suspects, err = extractor.TraceFingerprint(mixed, key, recipients, codeLength, DefaultWatermarkStrength)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	top := map[string]bool{suspects[0].RecipientID: true, suspects[1].RecipientID: true}
+	if !top["bob"] || !top["erin"] {
+		t.Fatalf("expected bob and erin to be the top suspects, got %v", suspects)
+	}
+}
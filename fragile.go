@@ -0,0 +1,134 @@
+package stegano
+
+import (
+	"image"
+	"image/color"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// TamperReport describes which blocks of a fragile watermarked image were modified.
+type TamperReport struct {
+	// BlockSize is the edge length of the verified blocks in pixels.
+	BlockSize int
+	// Blocks holds one row per block row, true for blocks that fail verification.
+	Blocks [][]bool
+	// Tampered is the number of blocks that fail verification.
+	Tampered int
+	// Mask is an image of the same size as the verified one, white where blocks were tampered with.
+	Mask *image.Gray
+}
+
+// IsTampered reports whether any block failed verification.
+func (r *TamperReport) IsTampered() bool {
+	return r.Tampered > 0
+}
+
+// EmbedFragileWatermark embeds a keyed HMAC of every block into that block's LSBs.
+// The HMAC covers the upper 7 bit-planes of the block, so any later edit shows up during verification.
+//
+// Parameters:
+// - coverImage: The image to protect.
+// - key: The secret key for the HMAC.
+// - blockSize: The edge length of the blocks in pixels (e.g. 8 or 32), smaller blocks localise tampering more precisely.
+func (m *EmbedHandler) EmbedFragileWatermark(coverImage image.Image, key []byte, blockSize int) (image.Image, error) {
+	if coverImage == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if len(key) == 0 {
+		return nil, ErrInvalidKey
+	}
+
+	if blockSize < 8 {
+		return nil, ErrInvalidBlockSize
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	watermarked, err := u.EmbedFragileWatermark(RGBchannels, width, height, key, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.SaveImage(watermarked, height, width)
+}
+
+// VerifyFragileWatermark checks every block of an image protected with EmbedFragileWatermark
+// and reports which blocks were tampered with.
+//
+// Parameters:
+// - img: The image to verify.
+// - key: The key used on embedding.
+// - blockSize: The block size used on embedding.
+func (m *ExtractHandler) VerifyFragileWatermark(img image.Image, key []byte, blockSize int) (*TamperReport, error) {
+	if img == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	height := img.Bounds().Dy()
+	width := img.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if len(key) == 0 {
+		return nil, ErrInvalidKey
+	}
+
+	if blockSize < 8 {
+		return nil, ErrInvalidBlockSize
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(img, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	tampered, err := u.VerifyFragileWatermark(RGBchannels, width, height, key, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	cols, rows := u.FragileGrid(width, height, blockSize)
+	report := &TamperReport{
+		BlockSize: blockSize,
+		Blocks:    make([][]bool, rows),
+		Mask:      image.NewGray(image.Rect(0, 0, width, height)),
+	}
+
+	for row := 0; row < rows; row++ {
+		report.Blocks[row] = tampered[row*cols : (row+1)*cols]
+		for col, bad := range report.Blocks[row] {
+			if !bad {
+				continue
+			}
+
+			report.Tampered++
+			for y := row * blockSize; y < min((row+1)*blockSize, height); y++ {
+				for x := col * blockSize; x < min((col+1)*blockSize, width); x++ {
+					report.Mask.SetGray(x, y, color.Gray{Y: 255})
+				}
+			}
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,44 @@
+package stegano
+
+import (
+	"image"
+	"testing"
+)
+
+func TestFragileWatermark_Tamper(t *testing.T) {
+	key := []byte("secret key")
+
+	watermarked, err := NewEmbedHandler().EmbedFragileWatermark(createTestImage(), key, 8)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	extractor := NewExtractHandler()
+	report, err := extractor.VerifyFragileWatermark(watermarked, key, 8)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if report.IsTampered() {
+		t.Fatalf("expected untouched image to verify, %d blocks tampered", report.Tampered)
+	}
+
+	// Paint over a pixel in block (5, 3)
+	edited := watermarked.(*image.RGBA)
+	c := edited.RGBAAt(42, 27)
+	c.G = 200
+	edited.SetRGBA(42, 27, c)
+
+	report, err = extractor.VerifyFragileWatermark(edited, key, 8)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if report.Tampered != 1 || !report.Blocks[3][5] {
+		t.Fatalf("expected only block (5, 3) to be tampered, got %d blocks", report.Tampered)
+	}
+
+	if report.Mask.GrayAt(40, 24).Y != 255 || report.Mask.GrayAt(0, 0).Y != 0 {
+		t.Fatal("mask does not match tampered blocks")
+	}
+}
@@ -24,6 +24,17 @@ func RsDecode(packedDataShards []byte, parity int) ([]byte, error) {
 	return u.RsDecode(packedDataShards, 1, parity)
 }
 
+// RsEncodeShards splits data into dataShards shards with parityShards parity shards.
+// The shard geometry and per-shard checksums are stored in the returned slice.
+func RsEncodeShards(data []byte, dataShards, parityShards int) ([]byte, error) {
+	return u.RsEncodeShards(data, dataShards, parityShards)
+}
+
+// RsDecodeShards reconstructs data packed by RsEncodeShards, treating shards with a bad checksum as missing.
+func RsDecodeShards(packedShards []byte) ([]byte, error) {
+	return u.RsDecodeShards(packedShards)
+}
+
 // GetImageCapacity calculates the maximum amount of data (in bytes)
 // that can be embedded in the given image, based on the specified bit depth.
 // Returns 0 if the bit depth exceeds 7, as higher depths are unsupported.
@@ -3,11 +3,14 @@ module github.com/scott-mescudi/stegano
 go 1.23.3
 
 require (
+	github.com/andybalholm/brotli v1.2.6
 	github.com/go-audio/audio v1.0.0
 	github.com/go-audio/wav v1.1.0
 	github.com/klauspost/compress v1.17.11
 	github.com/klauspost/reedsolomon v1.12.4
+	github.com/pierrec/lz4/v4 v4.1.33
 	github.com/stretchr/testify v1.10.0
+	github.com/ulikunitz/xz v0.5.17
 	golang.org/x/crypto v0.32.0
 )
 
@@ -1,14 +1,245 @@
 package stegano
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"image"
+	"io"
+	"slices"
 
+	c "github.com/scott-mescudi/stegano/compression"
 	u "github.com/scott-mescudi/stegano/pkg"
 )
 
+// embedIntoChannels embeds data with its length prefix into the RGB channels, filling the remaining
+// capacity with noise when requested by the options.
+// With error correction enabled the data is written as RS(255,223) codewords instead.
+// width is only used to compute the metrics requested with WithStats.
+func embedIntoChannels(RGBchannels []u.RgbChannel, data []byte, bitDepth uint8, width int, o *options) ([]u.RgbChannel, error) {
+	return recordStats(RGBchannels, width, o, func() ([]u.RgbChannel, error) {
+		return embedChannels(RGBchannels, data, bitDepth, o)
+	})
+}
+
+// recordStats runs embed and, when requested with WithStats, compares the channels before and after embedding.
+func recordStats(RGBchannels []u.RgbChannel, width int, o *options, embed func() ([]u.RgbChannel, error)) ([]u.RgbChannel, error) {
+	if o.stats == nil {
+		return embed()
+	}
+
+	cover := slices.Clone(RGBchannels)
+	embedded, err := embed()
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := compareChannels(cover, embedded, width)
+	if err != nil {
+		return nil, err
+	}
+
+	*o.stats = *stats
+	return embedded, nil
+}
+
+func embedChannels(RGBchannels []u.RgbChannel, data []byte, bitDepth uint8, o *options) ([]u.RgbChannel, error) {
+	if o.errorCorrection {
+		return embedWithErrorCorrection(RGBchannels, data, bitDepth, o)
+	}
+
+	if !o.noiseFill {
+		return u.EmbedIntoRGBchannelsWithDepthMonitored(RGBchannels, data, bitDepth, o.monitor())
+	}
+
+	noise, err := u.NewNoiseReader(o.noiseKey, RGBchannels, bitDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.EmbedIntoRGBchannelsWithNoiseMonitored(RGBchannels, data, bitDepth, noise, o.monitor())
+}
+
+func embedWithErrorCorrection(RGBchannels []u.RgbChannel, data []byte, bitDepth uint8, o *options) ([]u.RgbChannel, error) {
+	stream, err := u.ECCEncode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	order := u.SpreadOrder(0, len(RGBchannels)*3)
+	capacity := len(order) * (int(bitDepth) + 1) / 8
+	if len(stream) > capacity {
+		return nil, ErrDataTooLarge
+	}
+
+	if o.noiseFill {
+		noise, err := u.NewNoiseReader(o.noiseKey, RGBchannels, bitDepth)
+		if err != nil {
+			return nil, err
+		}
+
+		padding := make([]byte, capacity-len(stream))
+		if _, err := io.ReadFull(noise, padding); err != nil {
+			return nil, err
+		}
+		stream = append(stream, padding...)
+	}
+
+	return u.EmbedRawIntoRGBchannelsWithOrderMonitored(RGBchannels, stream, bitDepth, order, o.monitor())
+}
+
+// extractChannels reads the raw bytes embedded by embedChannels, following the spread order
+// used for error corrected streams.
+func extractChannels(RGBchannels []u.RgbChannel, bitDepth uint8, o *options) ([]byte, error) {
+	if o.errorCorrection {
+		return u.ExtractRawFromRGBchannelsWithOrderMonitored(RGBchannels, bitDepth, u.SpreadOrder(0, len(RGBchannels)*3), o.monitor())
+	}
+
+	return u.ExtractDataFromRGBchannelsWithDepthMonitored(RGBchannels, bitDepth, o.monitor())
+}
+
+// extractPayload returns the payload from the raw bytes extracted from the RGB channels,
+// either by reading its length prefix or by decoding the error correcting codewords.
+func extractPayload(data []byte, o *options) (payload []byte, err error) {
+	if o.errorCorrection {
+		return u.ECCDecode(data)
+	}
+
+	lenData, err := u.GetlenOfData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get length of extracted data: %w", err)
+	}
+
+	if lenData == 0 {
+		return nil, ErrInvalidDataLength
+	}
+
+	var moddedData = make([]byte, 0, lenData)
+	defer func() {
+		if r := recover(); r != nil {
+			payload = nil
+			err = fmt.Errorf("fatal error: %v", r)
+		}
+	}()
+
+	for i := 4; i < lenData+4; i++ {
+		if i >= len(data) {
+			return nil, fmt.Errorf("index out of range while accessing data: %d", i)
+		}
+		moddedData = append(moddedData, data[i])
+	}
+
+	return moddedData, nil
+}
+
+// compressData compresses data with the codec chosen by WithCodec or WithAutoCodec, framed with the codec ID
+// so c.Decompress can reverse it. By default zstd is used, or zstd with a registered dictionary if that is smaller.
+// If compression does not make the data smaller it is stored raw under the None codec instead.
+func compressData(data []byte, o *options) ([]byte, error) {
+	if o.autoCodec {
+		return c.CompressAuto(data)
+	}
+
+	var compressed []byte
+	var err error
+	if o.codec == nil {
+		compressed, err = c.CompressAuto(data, c.Zstd, c.ZstdDict)
+	} else {
+		compressed, err = c.Compress(o.codec, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(compressed) >= len(data)+1 {
+		return c.Compress(c.None, data)
+	}
+
+	return compressed, nil
+}
+
+// payloadVersion is the version of the payload format written by Encode, EncodeAuto and EmbedStream.
+const payloadVersion byte = 1
+
+// payloadHeader starts the payloads written by Encode, EncodeAuto and EmbedStream and is followed by the codec framed data.
+// Payloads without it were written before the codec frame existed and hold zstd compressed or raw data.
+var payloadHeader = []byte{0xFF, 'S', 'G', payloadVersion}
+
+// encodePayload puts the payload header in front of data, compressed with the chosen codec if compress is set.
+func encodePayload(data []byte, compress bool, o *options) ([]byte, error) {
+	frame, err := c.Compress(c.None, data)
+	if compress {
+		frame, err = compressData(data, o)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToCompressData, err)
+	}
+
+	return append(slices.Clone(payloadHeader), frame...), nil
+}
+
+// decodePayload reverses encodePayload. Legacy payloads without the header are decompressed if they are
+// zstd frames and returned as they are otherwise.
+func decodePayload(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, payloadHeader[:len(payloadHeader)-1]) {
+		if out, err := c.DecompressZSTD(data); err == nil {
+			return out, nil
+		}
+		return data, nil
+	}
+
+	if len(data) < len(payloadHeader) || data[len(payloadHeader)-1] != payloadVersion {
+		return nil, ErrPayloadVersion
+	}
+
+	out, err := c.Decompress(data[len(payloadHeader):])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress extracted data: %w", err)
+	}
+
+	return out, nil
+}
+
+// legacyParityShards is the parity used by SecureEmbedHandler before the shard format had a header.
+const legacyParityShards = 4
+
+// decodeShards reverses RsEncodeShards and falls back to the headerless single shard format
+// that SecureEmbedHandler wrote before.
+func decodeShards(data []byte) ([]byte, error) {
+	unpacked, err := u.RsDecodeShards(data)
+	if errors.Is(err, u.ErrNotShardFormat) || errors.Is(err, u.ErrCorruptShardHeader) {
+		if legacy, legacyErr := u.RsDecode(data, 1, legacyParityShards); legacyErr == nil {
+			return legacy, nil
+		}
+	}
+
+	return unpacked, err
+}
+
+// decryptData decrypts and decompresses data written by the secure handlers.
+// Images written before compression moved in front of encryption hold zstd compressed ciphertext, they are still read.
+func decryptData(data []byte, password string) ([]byte, error) {
+	if cipher, err := c.DecompressZSTD(data); err == nil {
+		if plain, err := DecryptData(cipher, password); err == nil {
+			return plain, nil
+		}
+	}
+
+	compressed, err := DecryptData(data, password)
+	if err != nil {
+		return nil, err
+	}
+
+	outdata, err := c.Decompress(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress extracted data: %w", err)
+	}
+
+	return outdata, nil
+}
+
 // EmbedDataIntoImage embeds the given data into the RGB channels of the specified image.
-func (m *EmbedHandler) EmbedDataIntoImage(coverImage image.Image, data []byte, bitDepth uint8) (image.Image, error) {
+func (m *EmbedHandler) EmbedDataIntoImage(coverImage image.Image, data []byte, bitDepth uint8, opts ...Option) (image.Image, error) {
 	if coverImage == nil {
 		return nil, ErrInvalidCoverImage
 	}
@@ -22,7 +253,7 @@ func (m *EmbedHandler) EmbedDataIntoImage(coverImage image.Image, data []byte, b
 		return nil, ErrDataTooLarge
 	}
 
-	embeddedRGBChannels, err := u.EmbedIntoRGBchannelsWithDepth(RGBchannels, data, bitDepth)
+	embeddedRGBChannels, err := embedIntoChannels(RGBchannels, data, bitDepth, coverImage.Bounds().Dx(), applyOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -1,22 +1,17 @@
 package stegano
 
 import (
-	"bufio"
-	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"image"
-	"os"
-	"path/filepath"
-	"runtime"
-	"sync"
 
-	c "github.com/scott-mescudi/stegano/compression"
 	u "github.com/scott-mescudi/stegano/pkg"
 )
 
 // EncodeAndSave embeds the provided data into the given image and saves the modified image to a new file.
-// The data is embedded using the specified bit depth. If `defaultCompression` is true, the data is compressed before embedding.
+// The data is embedded using the specified bit depth. If `defaultCompression` is true, the data is compressed before embedding,
+// with zstd unless WithCodec or WithAutoCodec picks another codec, and stored raw if that does not make it smaller.
 // Returns an error if the data exceeds the embedding capacity of the image or if the saving process fails.
 
 // Parameters:
@@ -25,7 +20,13 @@ import (
 // - bitDepth: The number of bits per channel used for embedding (0-7).
 // - outputFilename: The name of the file where the modified image will be saved.
 // - defaultCompression: A flag indicating whether the data should be compressed before embedding.
-func (m *EmbedHandler) Encode(coverImage image.Image, data []byte, bitDepth uint8, outputFilename string, defaultCompression bool) error {
+// - opts: Optional settings such as WithNoiseFill, WithErrorCorrection, WithCodec, WithProgress or WithStats.
+func (m *EmbedHandler) Encode(coverImage image.Image, data []byte, bitDepth uint8, outputFilename string, defaultCompression bool, opts ...Option) error {
+	return m.EncodeContext(context.Background(), coverImage, data, bitDepth, outputFilename, defaultCompression, opts...)
+}
+
+// EncodeContext is like Encode but stops with the context's error once ctx is cancelled.
+func (m *EmbedHandler) EncodeContext(ctx context.Context, coverImage image.Image, data []byte, bitDepth uint8, outputFilename string, defaultCompression bool, opts ...Option) error {
 	// Validate coverImage dimensions
 	if coverImage == nil {
 		return ErrInvalidCoverImage
@@ -60,18 +61,21 @@ func (m *EmbedHandler) Encode(coverImage image.Image, data []byte, bitDepth uint
 		return ErrDataTooLarge
 	}
 
-	// Compress data if required
-	var indata []byte = data
-	if defaultCompression {
-		compressedData, err := c.CompressZSTD(data)
-		if err != nil {
-			return ErrFailedToCompressData
-		}
-		indata = compressedData
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	o := applyOptions(opts)
+	o.ctx = ctx
+
+	// Compress data if required, the header and codec ID stored in front tell Decode how to read it
+	indata, err := encodePayload(data, defaultCompression, o)
+	if err != nil {
+		return err
 	}
 
 	// Embed data
-	embeddedRGBChannels, err := u.EmbedIntoRGBchannelsWithDepth(RGBchannels, indata, bitDepth)
+	embeddedRGBChannels, err := embedIntoChannels(RGBchannels, indata, bitDepth, width, o)
 	if err != nil {
 		return fmt.Errorf("failed to embed data into RGB channels: %w", err)
 	}
@@ -82,6 +86,10 @@ func (m *EmbedHandler) Encode(coverImage image.Image, data []byte, bitDepth uint
 		return ErrFailedToSaveImage
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// Use default filename if none provided
 	if outputFilename == "" {
 		outputFilename = DefaultOutputFile
@@ -90,15 +98,20 @@ func (m *EmbedHandler) Encode(coverImage image.Image, data []byte, bitDepth uint
 	return SaveImage(outputFilename, imgdata)
 }
 
-// Decode extracts data embedded in an image using the specified bit depth.
-// If the embedded data was compressed, it will be decompressed when `isDefaultCompressed` is true.
+// Decode extracts data embedded with Encode using the specified bit depth.
+// The data is decompressed with the codec recorded by Encode, if it was compressed at all.
 // Returns the extracted data or an error if the extraction or decompression fails.
 //
 // Parameters:
 // - coverImage: The image containing embedded data to be extracted.
 // - bitDepth: The bit depth used during the embedding process.
-// - isDefaultCompressed: A flag indicating whether the embedded data was compressed.
-func (m *ExtractHandler) Decode(coverImage image.Image, bitDepth uint8, isDefaultCompressed bool) ([]byte, error) {
+// - opts: Optional settings, must match the ones affecting the format used on embedding (e.g. WithErrorCorrection).
+func (m *ExtractHandler) Decode(coverImage image.Image, bitDepth uint8, opts ...Option) ([]byte, error) {
+	return m.DecodeContext(context.Background(), coverImage, bitDepth, opts...)
+}
+
+// DecodeContext is like Decode but stops with the context's error once ctx is cancelled.
+func (m *ExtractHandler) DecodeContext(ctx context.Context, coverImage image.Image, bitDepth uint8, opts ...Option) ([]byte, error) {
 	// Validate coverImage dimensions
 	if coverImage == nil {
 		return nil, ErrInvalidCoverImage
@@ -116,60 +129,49 @@ func (m *ExtractHandler) Decode(coverImage image.Image, bitDepth uint8, isDefaul
 		return nil, ErrFailedToExtractRGB
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	o := applyOptions(opts)
+	o.ctx = ctx
+
 	// Extract data
-	data, err := u.ExtractDataFromRGBchannelsWithDepth(RGBchannels, bitDepth)
+	data, err := extractChannels(RGBchannels, bitDepth, o)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, ErrFailedToExtractData
 	}
 
 	// Validate extracted data length
-	lenData, err := u.GetlenOfData(data)
+	moddedData, err := extractPayload(data, o)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get length of extracted data: %w", err)
-	}
-
-	if lenData == 0 {
-		return nil, ErrInvalidDataLength
-	}
-
-	var moddedData = make([]byte, 0, lenData)
-	defer func() {
-		if r := recover(); r != nil {
-			moddedData = nil
-			err = fmt.Errorf("fatal error: %v", r)
-		}
-	}()
-
-	for i := 4; i < lenData+4; i++ {
-		if i >= len(data) {
-			return nil, fmt.Errorf("index out of range while accessing data: %d", i)
-		}
-		moddedData = append(moddedData, data[i])
-	}
-
-	// Decompress data if required
-	if isDefaultCompressed {
-		outdata, err := c.DecompressZSTD(moddedData)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decompress extracted data: %w", err)
-		}
-		return outdata, nil
+		return nil, err
 	}
 
-	return moddedData, nil
+	return decodePayload(moddedData)
 }
 
-// Encode embeds data into a cover image using a specified bit depth, encrypts and compresses the data, and saves the resulting image to the specified output file.
-// Secure uses reed solomon codes for persistency
+// Encode embeds data into a cover image using a specified bit depth, compresses and encrypts the data, and saves the resulting image to the specified output file.
+// The data is stored raw when compression does not make it smaller.
+// Secure uses reed solomon codes for persistency, the shard geometry can be changed with WithReedSolomon and is stored alongside the data
 // Parameters:
 // - coverImage: The image to embed data into.
 // - data: The data to embed in the image.
 // - bitDepth: The bit depth used for embedding (valid range: 0-7).
 // - outputFilename: The file name to save the resulting image. Defaults to a pre-defined name if empty.
 // - password: The password used to encrypt the data.
+// - opts: Optional settings such as WithNoiseFill, WithReedSolomon, WithErrorCorrection, WithCodec, WithProgress or WithStats.
 // Returns:
 // - error: An error if any part of the embedding process fails.
-func (m *SecureEmbedHandler) Encode(coverImage image.Image, data []byte, bitDepth uint8, outputFilename string, password string) error {
+func (m *SecureEmbedHandler) Encode(coverImage image.Image, data []byte, bitDepth uint8, outputFilename string, password string, opts ...Option) error {
+	return m.EncodeContext(context.Background(), coverImage, data, bitDepth, outputFilename, password, opts...)
+}
+
+// EncodeContext is like Encode but stops with the context's error once ctx is cancelled.
+func (m *SecureEmbedHandler) EncodeContext(ctx context.Context, coverImage image.Image, data []byte, bitDepth uint8, outputFilename string, password string, opts ...Option) error {
 	// Validate coverImage dimensions
 	if coverImage == nil {
 		return ErrInvalidCoverImage
@@ -201,28 +203,34 @@ func (m *SecureEmbedHandler) Encode(coverImage image.Image, data []byte, bitDept
 		return ErrFailedToExtractRGB
 	}
 
-	maxCapacity := (len(RGBchannels) * 3 * (int(bitDepth) + 1)) / 8
-	if (((len(data)*8)+32)*5)+8 > maxCapacity {
-		return ErrDataTooLarge
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	cipher, err := EncryptData(data, password)
+	o := applyOptions(opts)
+	o.ctx = ctx
+
+	compressedData, err := compressData(data, o)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", ErrFailedToCompressData, err)
 	}
 
-	compressedData, err := c.CompressZSTD(cipher)
+	cipher, err := EncryptData(compressedData, password)
 	if err != nil {
-		return ErrFailedToCompressData
+		return err
 	}
 
-	RsData, err := u.RsEncode(compressedData, 4)
+	RsData, err := u.RsEncodeShards(cipher, o.dataShards, o.parityShards)
 	if err != nil {
 		return err
 	}
 
+	if (len(RsData)+4)*8 > len(RGBchannels)*3*(int(bitDepth)+1) {
+		return ErrDataTooLarge
+	}
+
 	// Embed data
-	embeddedRGBChannels, err := u.EmbedIntoRGBchannelsWithDepth(RGBchannels, RsData, bitDepth)
+	embeddedRGBChannels, err := embedIntoChannels(RGBchannels, RsData, bitDepth, width, o)
 	if err != nil {
 		return fmt.Errorf("failed to embed data into RGB channels: %w", err)
 	}
@@ -233,6 +241,10 @@ func (m *SecureEmbedHandler) Encode(coverImage image.Image, data []byte, bitDept
 		return ErrFailedToSaveImage
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// Use default filename if none provided
 	if outputFilename == "" {
 		outputFilename = DefaultOutputFile
@@ -242,15 +254,21 @@ func (m *SecureEmbedHandler) Encode(coverImage image.Image, data []byte, bitDept
 }
 
 // Decode extracts embedded data from a cover image using a specified bit depth, decrypts and decompresses it, and returns the original data.
-// Secure uses reed solomon codes for persistency
+// Secure uses reed solomon codes for persistency, shards failing their checksum are reconstructed from the parity shards
 // Parameters:
 // - coverImage: The image containing the embedded data.
 // - bitDepth: The bit depth used for extracting data (valid range: 0-7).
 // - password: The password used to decrypt the embedded data.
+// - opts: Optional settings, must match the ones affecting the format used on embedding (e.g. WithErrorCorrection).
 // Returns:
 // - []byte: The extracted original data.
 // - error: An error if the extraction process fails.
-func (m *SecureExtractHandler) Decode(coverImage image.Image, bitDepth uint8, password string) ([]byte, error) {
+func (m *SecureExtractHandler) Decode(coverImage image.Image, bitDepth uint8, password string, opts ...Option) ([]byte, error) {
+	return m.DecodeContext(context.Background(), coverImage, bitDepth, password, opts...)
+}
+
+// DecodeContext is like Decode but stops with the context's error once ctx is cancelled.
+func (m *SecureExtractHandler) DecodeContext(ctx context.Context, coverImage image.Image, bitDepth uint8, password string, opts ...Option) ([]byte, error) {
 	// Validate coverImage dimensions
 	if coverImage == nil {
 		return nil, ErrInvalidCoverImage
@@ -269,64 +287,37 @@ func (m *SecureExtractHandler) Decode(coverImage image.Image, bitDepth uint8, pa
 		return nil, ErrFailedToExtractRGB
 	}
 
-	// Extract data
-	data, err := u.ExtractDataFromRGBchannelsWithDepth(RGBchannels, bitDepth)
-	if err != nil {
-		return nil, ErrFailedToExtractData
-	}
-
-	// Validate extracted data length
-	lenData, err := u.GetlenOfData(data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get length of extracted data: %w", err)
-	}
-	if lenData == 0 {
-		return nil, errors.New("extracted data length is zero")
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	var moddedData = make([]byte, 0, lenData)
-	defer func() {
-		if r := recover(); r != nil {
-			moddedData = nil
-			err = fmt.Errorf("fatal error: %v", r)
-		}
-	}()
+	o := applyOptions(opts)
+	o.ctx = ctx
 
-	for i := 4; i < lenData+4; i++ {
-		if i >= len(data) {
-			return nil, fmt.Errorf("index out of range while accessing data: %d", i)
+	// Extract data
+	data, err := extractChannels(RGBchannels, bitDepth, o)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
 		}
-		moddedData = append(moddedData, data[i])
+		return nil, ErrFailedToExtractData
 	}
 
-	RsUnpacked, err := u.RsDecode(moddedData, 1, 4)
+	// Validate extracted data length
+	moddedData, err := extractPayload(data, o)
 	if err != nil {
 		return nil, err
 	}
 
-	outdata, err := c.DecompressZSTD(RsUnpacked)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decompress extracted data: %w", err)
-	}
-
-	return DecryptData(outdata, password)
-}
-
-func openFiles(coverImagePath, dataFilePath string) (coverImage image.Image, dataFile []byte, err error) {
-	cimg, err := Decodeimage(coverImagePath)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	df, err := os.ReadFile(dataFilePath)
+	RsUnpacked, err := decodeShards(moddedData)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	return cimg, df, nil
+	return decryptData(RsUnpacked, password)
 }
 
-// EmbedFile embeds data from a file into an image using a default bit depth of 1 (the last two bits in a byte).
+// EmbedFile embeds a single file into an image, see EmbedFiles for the format.
 // The data is first compressed and encrypted with the provided password before embedding into the image.
 // Returns an error if the process fails at any stage.
 //
@@ -335,184 +326,35 @@ func openFiles(coverImagePath, dataFilePath string) (coverImage image.Image, dat
 // - dataFilePath: The file path of the data to embed.
 // - outputFilePath: The file path to save the resulting image with embedded data.
 // - password: A password used to encrypt the data before embedding.
-
-// ExtractFile extracts embedded data from an image using a default bit depth of 1 (the last two bits in a byte).
-// The embedded data is decrypted and decompressed using the provided password.
-// The extracted file is saved using its original name (stored within the embedded data).
-// Returns an error if the process fails at any stage.
-//
-// Parameters:
-// - coverImagePath: The file path of the image containing embedded data.
-// - password: A password used to decrypt the embedded data after extraction.
+// - bitDepth: The bit depth used for embedding (valid range: 0-7).
 func EmbedFile(coverImagePath, dataFilePath, outputFilePath, password string, bitDepth uint8) error {
-	if coverImagePath == "" {
-		return errors.New("invalid coverImagePath")
-	}
-
 	if dataFilePath == "" {
 		return errors.New("invalid dataFilePath")
 	}
 
-	if outputFilePath == "" {
-		return errors.New("invalid outputFilePath")
-	}
-
-	if password == "" {
-		return errors.New("invalid password")
-	}
-
-	if bitDepth > 7 {
-		return ErrDepthOutOfRange
-	}
-
-	if ext := filepath.Ext(outputFilePath); ext != ".png" {
-		return fmt.Errorf("output file must have a .png extension, got '%s'", ext)
-	}
-
-	fp := filepath.Base(dataFilePath)
-	ext := fmt.Sprintf("/-%s-/\n", fp)
-
-	cf, df, err := openFiles(coverImagePath, dataFilePath)
-	if err != nil {
-		return err
-	}
-
-	df = append([]byte(ext), df...)
-
-	var (
-		wg       sync.WaitGroup
-		erchan   = make(chan error)
-		channels []u.RgbChannel
-	)
-
-	wg.Add(2)
-
-	go func() {
-		defer wg.Done()
-		channels = u.ExtractRGBChannelsFromImageWithConCurrency(cf, runtime.NumCPU())
-		if (len(df)*8)+32 > len(channels)*3*(int(bitDepth)+1) {
-			erchan <- fmt.Errorf("error: Data too large to embed into the image")
-			return
-		}
-	}()
-
-	go func() {
-		defer wg.Done()
-		df, err = c.CompressZSTD(df)
-		if err != nil {
-			erchan <- err
-			return
-		}
-
-		df, err = u.Encrypt(password, df)
-		if err != nil {
-			erchan <- err
-			return
-		}
-	}()
-
-	select {
-	case <-erchan:
-		return err
-	default:
-	}
-
-	wg.Wait()
-
-	channels, err = u.EmbedIntoRGBchannelsWithDepth(channels, df, bitDepth)
-	if err != nil {
-		return err
-	}
-
-	newImage, err := u.SaveImage(channels, cf.Bounds().Max.Y, cf.Bounds().Max.X)
-	if err != nil {
-		return nil
-	}
-
-	return SaveImage(outputFilePath, newImage)
+	return EmbedFiles(coverImagePath, []string{dataFilePath}, outputFilePath, password, bitDepth)
 }
 
-// ExtractFile extracts embedded data from an image using a default bit depth of 1 (the last two bits in a byte).
-// The embedded data is decrypted and decompressed using the provided password.
-// The extracted file is saved using its original name (stored within the embedded data).
+// ExtractFile extracts the files embedded with EmbedFile or EmbedFiles from an image and writes them to disk.
+// The embedded data is decrypted and decompressed using the provided password. Images written by
+// EmbedFile before it stored an archive are read as well.
+// Files are written below the current directory, or the one set with WithOutputDir, and names that would
+// escape it are rejected. Existing files are not touched unless WithOverwritePolicy says otherwise.
+// Use ExtractFiles to get the contents in memory instead.
 // Returns an error if the process fails at any stage.
 //
 // Parameters:
 // - coverImagePath: The file path of the image containing embedded data.
 // - password: A password used to decrypt the embedded data after extraction.
-func ExtractFile(coverImagePath, password string, bitDepth uint8) error {
-	if coverImagePath == "" {
-		return errors.New("invalid coverImagePath")
-	}
-
-	if password == "" {
-		return errors.New("invalid password")
-	}
-
-	if bitDepth > 7 {
-		return ErrDepthOutOfRange
-	}
-
-	cf, err := Decodeimage(coverImagePath)
-	if err != nil {
-		return err
-	}
-
-	channels := u.ExtractRGBChannelsFromImageWithConCurrency(cf, runtime.NumCPU())
-	embeddedData, err := u.ExtractDataFromRGBchannelsWithDepth(channels, bitDepth)
-	if err != nil {
-		return err
-	}
-
-	lenData, err := u.GetlenOfData(embeddedData)
-	if err != nil {
-		return err
-	}
-
-	var cipherText = make([]byte, 0, lenData)
-	defer func() {
-		if r := recover(); r != nil {
-			cipherText = nil
-			err = fmt.Errorf("fatal error: %v", r)
-		}
-	}()
-
-	for i := 4; i < lenData+4; i++ {
-		if i >= len(embeddedData) {
-			return fmt.Errorf("index out of range while accessing data: %d", i)
-		}
-		cipherText = append(cipherText, embeddedData[i])
-	}
-
-	plaintext, err := u.Decrypt(password, cipherText)
-	if err != nil {
-		return err
-	}
-
-	plaintext, err = c.DecompressZSTD(plaintext)
-	if err != nil {
-		return err
-	}
-
-	scanner := bufio.NewScanner(bytes.NewReader(plaintext))
-
-	var filename string
-	var size int
-	if scanner.Scan() {
-		filename = scanner.Text()
-		size = len(scanner.Bytes())
-	}
-
-	ff, err := os.Create(filename[2 : len(filename)-2])
-	if err != nil {
-		return err
-	}
-	defer ff.Close()
-
-	_, err = ff.Write(plaintext[size+1:])
+// - bitDepth: The bit depth used when embedding.
+// - opts: Optional settings such as WithOutputDir or WithOverwritePolicy.
+func ExtractFile(coverImagePath, password string, bitDepth uint8, opts ...Option) error {
+	files, err := ExtractFiles(coverImagePath, password, bitDepth)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	o := applyOptions(opts)
+	_, err = WriteFiles(files, o.outputDir, o.overwrite)
+	return err
 }
@@ -1,11 +1,17 @@
 package stegano
 
 import (
+	"bytes"
+	"crypto/rand"
 	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"os"
 	"testing"
+
+	c "github.com/scott-mescudi/stegano/compression"
+	u "github.com/scott-mescudi/stegano/pkg"
 )
 
 // Mock function to create a test image
@@ -169,3 +175,422 @@ func TestEncode_NullImage(t *testing.T) {
 		t.Fatalf("expected an error, got nil")
 	}
 }
+
+func TestEncode_NoiseFill(t *testing.T) {
+	// Setup
+	coverImage := createTestImage()
+	data := []byte("noise filled data")
+	bitDepth := uint8(1)
+	outputFilename := "test_noise_output.png"
+	defer os.Remove(outputFilename)
+
+	// Execute
+	handler := &EmbedHandler{3}
+	err := handler.Encode(coverImage, data, bitDepth, outputFilename, true, WithKeyedNoiseFill([]byte("key")))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	img, err := Decodeimage(outputFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The last row is far beyond the payload and should carry noise
+	unchanged := 0
+	for x := 0; x < 100; x++ {
+		r, g, b, _ := img.At(x, 99).RGBA()
+		if r>>8 == 255 && g>>8 == 0 && b>>8 == 0 {
+			unchanged++
+		}
+	}
+
+	if unchanged > 50 {
+		t.Fatalf("expected remaining capacity to be filled with noise, %d pixels unchanged", unchanged)
+	}
+
+	extracted, err := NewExtractHandler().Decode(img, bitDepth)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if string(extracted) != string(data) {
+		t.Fatalf("expected %q, got %q", data, extracted)
+	}
+}
+
+func TestSecureEncodeDecode_ReedSolomon(t *testing.T) {
+	// Setup
+	coverImage := createTestImage()
+	data := []byte("secure test data")
+	bitDepth := uint8(2)
+	outputFilename := "test_secure_output.png"
+	defer os.Remove(outputFilename)
+
+	// Execute
+	handler := NewSecureEmbedHandler()
+	err := handler.Encode(coverImage, data, bitDepth, outputFilename, "password", WithReedSolomon(3, 2))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	img, err := Decodeimage(outputFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extracted, err := NewSecureExtractHandler().Decode(img, bitDepth, "password")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if string(extracted) != string(data) {
+		t.Fatalf("expected %q, got %q", data, extracted)
+	}
+}
+
+func TestEncodeDecode_ErrorCorrection(t *testing.T) {
+	// Setup
+	coverImage := createTestImage()
+	data := []byte("error corrected test data")
+	bitDepth := uint8(0)
+	outputFilename := "test_ecc_output.png"
+	defer os.Remove(outputFilename)
+
+	// Execute
+	handler := &EmbedHandler{3}
+	err := handler.Encode(coverImage, data, bitDepth, outputFilename, false, WithErrorCorrection())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	img, err := Decodeimage(outputFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Flip the LSB of a few scattered pixels
+	stego := img.(*image.RGBA)
+	for _, x := range []int{3, 17, 42, 60, 91} {
+		c := stego.RGBAAt(x, 0)
+		c.R ^= 1
+		stego.SetRGBA(x, 0, c)
+	}
+
+	extracted, err := NewExtractHandler().Decode(stego, bitDepth, WithErrorCorrection())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if string(extracted) != string(data) {
+		t.Fatalf("expected %q, got %q", data, extracted)
+	}
+}
+
+func TestEncodeDecode_ErrorCorrectionDamagedRow(t *testing.T) {
+	coverImage := createTestImage()
+	data := make([]byte, 300)
+	rand.Read(data)
+	bitDepth := uint8(7)
+	outputFilename := "test_ecc_row_output.png"
+	defer os.Remove(outputFilename)
+
+	handler := &EmbedHandler{3}
+	if err := handler.Encode(coverImage, data, bitDepth, outputFilename, false, WithErrorCorrection()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	img, err := Decodeimage(outputFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Invert the whole first row, which would hold the start of the stream if it was embedded in raster order
+	stego := img.(*image.RGBA)
+	for x := 0; x < stego.Bounds().Dx(); x++ {
+		c := stego.RGBAAt(x, 0)
+		c.R, c.G, c.B = ^c.R, ^c.G, ^c.B
+		stego.SetRGBA(x, 0, c)
+	}
+
+	extracted, err := NewExtractHandler().Decode(stego, bitDepth, WithErrorCorrection())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if !bytes.Equal(extracted, data) {
+		t.Fatal("decoded data does not match original")
+	}
+}
+
+func TestEncodeDecode_Codecs(t *testing.T) {
+	coverImage := createTestImage()
+	data := []byte("codec test data, codec test data, codec test data")
+	bitDepth := uint8(1)
+	outputFilename := "test_codec_output.png"
+	defer os.Remove(outputFilename)
+
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{name: "brotli", opt: WithCodec(c.Brotli)},
+		{name: "zstd level 19", opt: WithCodec(c.ZstdLevel(19))},
+		{name: "xz", opt: WithCodec(c.XZ)},
+		{name: "auto", opt: WithAutoCodec()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &EmbedHandler{3}
+			if err := handler.Encode(coverImage, data, bitDepth, outputFilename, true, tt.opt); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			img, err := Decodeimage(outputFilename)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the codec is read from the payload, Decode needs no option
+			extracted, err := NewExtractHandler().Decode(img, bitDepth)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if string(extracted) != string(data) {
+				t.Fatalf("expected %q, got %q", data, extracted)
+			}
+		})
+	}
+}
+
+func TestEncodeDecode_ZstdDictionary(t *testing.T) {
+	var samples [][]byte
+	for i := 0; i < 200; i++ {
+		samples = append(samples, []byte(fmt.Sprintf(`{"event":"login","user":"user-%d","ok":true}`, i)))
+	}
+
+	dict, err := c.TrainZstdDictionary(samples, 31, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := c.RegisterZstdDictionary(dict)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coverImage := createTestImage()
+	data := []byte(`{"event":"login","user":"user-4242","ok":true}`)
+	outputFilename := "test_dict_output.png"
+	defer os.Remove(outputFilename)
+
+	handler := &EmbedHandler{3}
+	if err := handler.Encode(coverImage, data, LSB, outputFilename, true, WithCodec(c.ZstdDictionary(id, c.DefaultZstdLevel))); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	img, err := Decodeimage(outputFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extracted, err := NewExtractHandler().Decode(img, LSB)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if string(extracted) != string(data) {
+		t.Fatalf("expected %q, got %q", data, extracted)
+	}
+
+	// without a codec option a registered dictionary is used when it compresses better
+	framed, err := compressData(data, applyOptions(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if framed[0] != c.IDZstdDict {
+		t.Fatalf("expected codec ID %d, got %d", c.IDZstdDict, framed[0])
+	}
+}
+
+func TestCompressData_FallsBackToRaw(t *testing.T) {
+	random := make([]byte, 256)
+	rand.Read(random)
+
+	tests := []struct {
+		name string
+		data []byte
+		want byte
+	}{
+		{name: "random", data: random, want: c.IDNone},
+		{name: "tiny", data: []byte("hi"), want: c.IDNone},
+		{name: "repetitive", data: bytes.Repeat([]byte("abcd"), 100), want: c.IDZstd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := compressData(tt.data, applyOptions(nil))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if out[0] != tt.want {
+				t.Fatalf("expected codec %d, got %d", tt.want, out[0])
+			}
+
+			if len(out) > len(tt.data)+1 {
+				t.Fatalf("expected at most %d bytes, got %d", len(tt.data)+1, len(out))
+			}
+		})
+	}
+}
+
+func TestDecryptData_Legacy(t *testing.T) {
+	data := []byte("written before compression moved in front of encryption")
+
+	cipher, err := EncryptData(data, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy, err := c.CompressZSTD(cipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := decryptData(legacy, "password")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if string(got) != string(data) {
+		t.Fatalf("expected %q, got %q", data, got)
+	}
+}
+
+func TestSecureDecode_LegacyShards(t *testing.T) {
+	data := []byte("written before the shard format had a header")
+
+	cipher, err := EncryptData(data, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compressed, err := c.CompressZSTD(cipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the single data shard format SecureEmbedHandler used to write
+	legacy, err := u.RsEncode(compressed, legacyParityShards)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	channels := u.ExtractRGBChannelsFromImageWithConCurrency(createTestImage(), 1)
+	embedded, err := u.EmbedIntoRGBchannelsWithDepth(channels, legacy, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := u.SaveImage(embedded, 100, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := NewSecureExtractHandler().Decode(img, 3, "password")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if string(got) != string(data) {
+		t.Fatalf("expected %q, got %q", data, got)
+	}
+}
+
+func TestDecode_LegacyPayload(t *testing.T) {
+	raw := []byte{0x01, 'w', 'r', 'i', 't', 't', 'e', 'n', ' ', 'r', 'a', 'w'}
+	compressed, err := c.CompressZSTD([]byte("written with zstd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		payload []byte
+		want    []byte
+	}{
+		{name: "Uncompressed", payload: raw, want: raw},
+		{name: "Compressed", payload: compressed, want: []byte("written with zstd")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// payloads used to be embedded without the payload header and codec ID
+			channels := u.ExtractRGBChannelsFromImageWithConCurrency(createTestImage(), 1)
+			embedded, err := u.EmbedIntoRGBchannelsWithDepth(channels, tt.payload, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			img, err := u.SaveImage(embedded, 100, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := NewExtractHandler().Decode(img, 2)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if !bytes.Equal(got, tt.want) {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+
+			channels = u.ExtractRGBChannelsFromImageWithConCurrency(createTestImage(), 1)
+			embedded, err = embedAuto(channels, tt.payload, 100, 100, applyOptions(nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			img, err = u.SaveImage(embedded, 100, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err = NewExtractHandler().DecodeAuto(img)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if !bytes.Equal(got, tt.want) {
+				t.Fatalf("DecodeAuto: expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	future := append([]byte{0xFF, 'S', 'G', payloadVersion + 1}, raw...)
+	if _, err := decodePayload(future); !errors.Is(err, ErrPayloadVersion) {
+		t.Fatalf("expected error: %v, got: %v", ErrPayloadVersion, err)
+	}
+}
+
+func TestDecodeShards_ShortLegacy(t *testing.T) {
+	// legacy payloads can be shorter than the shard header
+	legacy, err := u.RsEncode([]byte("hi"), legacyParityShards)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := decodeShards(legacy)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if string(got) != "hi" {
+		t.Fatalf("expected %q, got %q", "hi", got)
+	}
+}
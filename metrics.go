@@ -0,0 +1,148 @@
+package stegano
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"runtime"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// ImageMetrics describes how much a stego image differs from its cover.
+type ImageMetrics struct {
+	// MSE is the mean squared error over all channel samples.
+	MSE float64
+	// PSNR is the peak signal to noise ratio in dB, +Inf if the images are identical.
+	PSNR float64
+	// SSIM is the structural similarity, 1 if the images are identical.
+	SSIM float64
+	// ModifiedChannels is the number of channel samples that changed.
+	ModifiedChannels int
+	// ModifiedPercent is ModifiedChannels as a percentage of all channel samples.
+	ModifiedPercent float64
+}
+
+// AudioMetrics describes how much a stego WAV file differs from its cover.
+type AudioMetrics struct {
+	// SNR is the signal to noise ratio in dB, +Inf if the files are identical.
+	SNR float64
+	// SegmentalSNR is the average SNR of 256 sample frames in dB.
+	SegmentalSNR float64
+	// ModifiedSamples is the number of samples that changed.
+	ModifiedSamples int
+	// ModifiedPercent is ModifiedSamples as a percentage of all samples.
+	ModifiedPercent float64
+}
+
+func compareChannels(cover, stego []u.RgbChannel, width int) (*ImageMetrics, error) {
+	mse, err := u.MSE(cover, stego)
+	if err != nil {
+		return nil, err
+	}
+
+	ssim, err := u.SSIM(cover, stego, width)
+	if err != nil {
+		return nil, err
+	}
+
+	modified := u.ModifiedChannels(cover, stego)
+	return &ImageMetrics{
+		MSE:              mse,
+		PSNR:             u.PSNR(mse),
+		SSIM:             ssim,
+		ModifiedChannels: modified,
+		ModifiedPercent:  float64(modified) * 100 / float64(len(cover)*3),
+	}, nil
+}
+
+// CompareImages computes quality metrics of a stego image against its cover.
+//
+// Parameters:
+// - coverImage: The original image.
+// - stegoImage: The image with embedded data, must have the same dimensions as coverImage.
+func CompareImages(coverImage, stegoImage image.Image) (*ImageMetrics, error) {
+	if coverImage == nil || stegoImage == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	width, height := coverImage.Bounds().Dx(), coverImage.Bounds().Dy()
+	if width <= 0 || height <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if stegoImage.Bounds().Dx() != width || stegoImage.Bounds().Dy() != height {
+		return nil, ErrDimensionMismatch
+	}
+
+	cover := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, runtime.NumCPU())
+	stego := u.ExtractRGBChannelsFromImageWithConCurrency(stegoImage, runtime.NumCPU())
+	if cover == nil || stego == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	return compareChannels(cover, stego, width)
+}
+
+// CompareAudio computes quality metrics of a stego WAV file against its cover.
+//
+// Parameters:
+// - coverFilename: The original WAV file.
+// - stegoFilename: The WAV file with embedded data.
+func CompareAudio(coverFilename, stegoFilename string) (*AudioMetrics, error) {
+	cover, err := readWAV(coverFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	stego, err := readWAV(stegoFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cover.Data) != len(stego.Data) {
+		return nil, ErrDimensionMismatch
+	}
+
+	snr, err := u.SNR(cover.Data, stego.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	segmental, err := u.SegmentalSNR(cover.Data, stego.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	modified := 0
+	for i := range cover.Data {
+		if cover.Data[i] != stego.Data[i] {
+			modified++
+		}
+	}
+
+	return &AudioMetrics{
+		SNR:             snr,
+		SegmentalSNR:    segmental,
+		ModifiedSamples: modified,
+		ModifiedPercent: float64(modified) * 100 / float64(len(cover.Data)),
+	}, nil
+}
+
+// readWAV reads all samples of a WAV file and closes it.
+func readWAV(filename string) (*audio.IntBuffer, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	decoder := wav.NewDecoder(f)
+	if !decoder.IsValidFile() {
+		return nil, fmt.Errorf("%s: %w", filename, ErrInvalidAudio)
+	}
+
+	return decoder.FullPCMBuffer()
+}
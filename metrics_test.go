@@ -0,0 +1,130 @@
+package stegano
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+)
+
+func TestCompareImages(t *testing.T) {
+	cover := createGradientImage(64, 64)
+
+	same, err := CompareImages(cover, cover)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if same.MSE != 0 || !math.IsInf(same.PSNR, 1) || same.SSIM != 1 || same.ModifiedChannels != 0 {
+		t.Fatalf("expected identical metrics, got %+v", same)
+	}
+
+	stego, err := NewEmbedHandler().EmbedDataIntoImage(cover, []byte("metrics test"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics, err := CompareImages(cover, stego)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if metrics.ModifiedChannels == 0 || metrics.PSNR < 30 || metrics.SSIM >= 1 {
+		t.Fatalf("unexpected metrics %+v", metrics)
+	}
+
+	if want := float64(metrics.ModifiedChannels) * 100 / (64 * 64 * 3); metrics.ModifiedPercent != want {
+		t.Fatalf("expected %v%% modified, got %v", want, metrics.ModifiedPercent)
+	}
+}
+
+func TestEncode_WithStats(t *testing.T) {
+	coverImage := createGradientImage(64, 64)
+	outputFilename := "test_stats_output.png"
+	defer os.Remove(outputFilename)
+
+	var stats ImageMetrics
+	err := NewEmbedHandler().Encode(coverImage, []byte("stats test data"), 1, outputFilename, false, WithStats(&stats))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	img, err := Decodeimage(outputFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := CompareImages(coverImage, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats != *want {
+		t.Fatalf("expected stats %+v, got %+v", *want, stats)
+	}
+}
+
+func writeTestWAV(t *testing.T, filename string, samples []int) {
+	t.Helper()
+
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	enc := wav.NewEncoder(f, 8000, 16, 1, 1)
+	buf := &audio.IntBuffer{Format: &audio.Format{NumChannels: 1, SampleRate: 8000}, Data: samples, SourceBitDepth: 16}
+	if err := enc.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompareAudio(t *testing.T) {
+	dir := t.TempDir()
+	original := make([]int, 4000)
+	modified := make([]int, 4000)
+	for i := range original {
+		original[i] = int(8000 * math.Sin(float64(i)/7))
+		modified[i] = original[i] ^ 1
+	}
+
+	coverFile := filepath.Join(dir, "cover.wav")
+	stegoFile := filepath.Join(dir, "stego.wav")
+	writeTestWAV(t, coverFile, original)
+	writeTestWAV(t, stegoFile, modified)
+
+	metrics, err := CompareAudio(coverFile, stegoFile)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if metrics.ModifiedSamples != 4000 || metrics.ModifiedPercent != 100 {
+		t.Fatalf("expected every sample to be modified, got %+v", metrics)
+	}
+
+	if metrics.SNR < 60 || metrics.SegmentalSNR > 35 {
+		t.Fatalf("unexpected SNR values %+v", metrics)
+	}
+
+	if _, err := CompareAudio(coverFile, filepath.Join(dir, "missing.wav")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected error: %v, got: %v", os.ErrNotExist, err)
+	}
+
+	invalidFile := filepath.Join(dir, "invalid.wav")
+	if err := os.WriteFile(invalidFile, []byte("not a wav file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CompareAudio(coverFile, invalidFile); !errors.Is(err, ErrInvalidAudio) {
+		t.Fatalf("expected error: %v, got: %v", ErrInvalidAudio, err)
+	}
+}
@@ -0,0 +1,155 @@
+package stegano
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// EmbedAcrossCarriers splits data over the carriers in proportion to their capacity at bitDepth.
+// Every piece is tagged with a random set ID and its sequence number, so ExtractAcrossCarriers can
+// reassemble the data from the returned carriers in any order. Every carrier receives a piece, even
+// if its share of the data is empty.
+//
+// Parameters:
+// - carriers: The carriers to embed into, e.g. ImageCarrier or AudioCarrier values.
+// - data: The data to split.
+// - bitDepth: The bit depth used for embedding (valid range: 0-7).
+func EmbedAcrossCarriers(carriers []Carrier, data []byte, bitDepth uint8) ([]Carrier, error) {
+	if len(carriers) == 0 || len(carriers) > 0xFFFF {
+		return nil, ErrInvalidCarriers
+	}
+
+	if len(data) == 0 {
+		return nil, ErrInvalidData
+	}
+
+	if bitDepth > MaxBitDepth {
+		return nil, ErrDepthOutOfRange
+	}
+
+	capacities := make([]int, len(carriers))
+	for i, c := range carriers {
+		if c == nil {
+			return nil, ErrInvalidCarriers
+		}
+
+		capacities[i] = c.Capacity(bitDepth) - u.PieceHeaderSize
+		if capacities[i] < 0 {
+			return nil, fmt.Errorf("carrier %d cannot hold a piece header: %w", i, ErrDataTooLarge)
+		}
+	}
+
+	sizes := u.SplitSizes(len(data), capacities)
+	if sizes == nil {
+		return nil, ErrDataTooLarge
+	}
+
+	header := u.PieceHeader{
+		Kind:      u.PieceSplit,
+		Total:     uint16(len(carriers)),
+		Threshold: uint16(len(carriers)),
+		Length:    uint32(len(data)),
+		Checksum:  u.Checksum(data),
+	}
+	if _, err := rand.Read(header.SetID[:]); err != nil {
+		return nil, err
+	}
+
+	out := make([]Carrier, len(carriers))
+	offset := 0
+	for i, c := range carriers {
+		header.Seq = uint16(i)
+		embedded, err := c.Embed(u.MarshalPiece(header, data[offset:offset+sizes[i]]), bitDepth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed piece %d: %w", i, err)
+		}
+
+		out[i] = embedded
+		offset += sizes[i]
+	}
+
+	return out, nil
+}
+
+// readPieces extracts the pieces of one set from the carriers, indexed by sequence number.
+// Carriers without a piece are skipped, pieces from more than one set are rejected.
+func readPieces(carriers []Carrier, bitDepth uint8, kind uint8) (u.PieceHeader, map[uint16][]byte, error) {
+	var header u.PieceHeader
+	pieces := make(map[uint16][]byte)
+
+	for _, c := range carriers {
+		if c == nil {
+			continue
+		}
+
+		data, err := c.Extract(bitDepth)
+		if err != nil {
+			continue
+		}
+
+		h, body, err := u.ParsePiece(data)
+		if err != nil || h.Kind != kind {
+			continue
+		}
+
+		// the checksum of a share covers its own body, corrupt shares are left out
+		if kind == u.PieceShare && u.Checksum(body) != h.Checksum {
+			continue
+		}
+
+		if len(pieces) == 0 {
+			header = h
+		} else if h.SetID != header.SetID || h.Total != header.Total || h.Threshold != header.Threshold {
+			return header, nil, ErrMixedSets
+		}
+
+		pieces[h.Seq] = body
+	}
+
+	if len(pieces) == 0 {
+		return header, nil, ErrNoPieces
+	}
+
+	return header, pieces, nil
+}
+
+// ExtractAcrossCarriers reassembles data embedded with EmbedAcrossCarriers. The carriers may be given in any order,
+// if pieces are missing the returned error wraps ErrMissingPieces and names the missing sequence numbers.
+//
+// Parameters:
+// - carriers: The carriers holding the pieces.
+// - bitDepth: The bit depth used during embedding.
+func ExtractAcrossCarriers(carriers []Carrier, bitDepth uint8) ([]byte, error) {
+	if bitDepth > MaxBitDepth {
+		return nil, ErrDepthOutOfRange
+	}
+
+	header, pieces, err := readPieces(carriers, bitDepth, u.PieceSplit)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []uint16
+	data := make([]byte, 0, header.Length)
+	for seq := uint16(0); seq < header.Total; seq++ {
+		body, ok := pieces[seq]
+		if !ok {
+			missing = append(missing, seq)
+			continue
+		}
+
+		data = append(data, body...)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %d of %d missing %v", ErrMissingPieces, len(missing), header.Total, missing)
+	}
+
+	if len(data) != int(header.Length) || u.Checksum(data) != header.Checksum {
+		return nil, ErrChecksumMismatch
+	}
+
+	return data, nil
+}
@@ -0,0 +1,92 @@
+package stegano
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/go-audio/audio"
+)
+
+func createTestAudioCarrier(samples int) *AudioCarrier {
+	data := make([]int, samples)
+	for i := range data {
+		data[i] = int(8000 * math.Sin(float64(i)/7))
+	}
+
+	return &AudioCarrier{
+		Buffer:     &audio.IntBuffer{Format: &audio.Format{NumChannels: 1, SampleRate: 8000}, Data: data, SourceBitDepth: 16},
+		SampleRate: 8000,
+		BitDepth:   16,
+		NumChans:   1,
+	}
+}
+
+func TestEmbedAcrossCarriers(t *testing.T) {
+	carriers := []Carrier{
+		NewImageCarrier(createGradientImage(32, 32)),
+		createTestAudioCarrier(20000),
+		NewImageCarrier(createGradientImage(64, 64)),
+	}
+
+	data := make([]byte, 3000)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	embedded, err := EmbedAcrossCarriers(carriers, data, 1)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// the largest carrier should receive the largest piece
+	first, _ := embedded[0].Extract(1)
+	last, _ := embedded[2].Extract(1)
+	if len(last) <= len(first) {
+		t.Fatalf("expected pieces proportional to capacity, got %d and %d bytes", len(first), len(last))
+	}
+
+	shuffled := []Carrier{embedded[2], embedded[0], embedded[1]}
+	extracted, err := ExtractAcrossCarriers(shuffled, 1)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if !bytes.Equal(extracted, data) {
+		t.Fatal("reassembled data does not match")
+	}
+
+	if _, err := ExtractAcrossCarriers(embedded[:2], 1); !errors.Is(err, ErrMissingPieces) {
+		t.Fatalf("expected error: %v, got: %v", ErrMissingPieces, err)
+	}
+}
+
+func TestEmbedAcrossCarriers_Errors(t *testing.T) {
+	carriers := []Carrier{NewImageCarrier(createGradientImage(16, 16)), NewImageCarrier(createGradientImage(16, 16))}
+
+	if _, err := EmbedAcrossCarriers(carriers, make([]byte, 1000), LSB); !errors.Is(err, ErrDataTooLarge) {
+		t.Fatalf("expected error: %v, got: %v", ErrDataTooLarge, err)
+	}
+
+	if _, err := EmbedAcrossCarriers(nil, []byte("data"), LSB); !errors.Is(err, ErrInvalidCarriers) {
+		t.Fatalf("expected error: %v, got: %v", ErrInvalidCarriers, err)
+	}
+
+	first, err := EmbedAcrossCarriers(carriers, []byte("first payload"), LSB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := EmbedAcrossCarriers(carriers, []byte("second payload"), LSB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ExtractAcrossCarriers([]Carrier{first[0], second[1]}, LSB); !errors.Is(err, ErrMixedSets) {
+		t.Fatalf("expected error: %v, got: %v", ErrMixedSets, err)
+	}
+
+	if _, err := ExtractAcrossCarriers(carriers, LSB); !errors.Is(err, ErrNoPieces) {
+		t.Fatalf("expected error: %v, got: %v", ErrNoPieces, err)
+	}
+}
@@ -0,0 +1,177 @@
+package stegano
+
+import (
+	"context"
+
+	c "github.com/scott-mescudi/stegano/compression"
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// Option configures optional behaviour of the embedding and extraction methods.
+type Option func(*options)
+
+type options struct {
+	noiseFill    bool
+	noiseKey     []byte
+	dataShards   int
+	parityShards int
+
+	errorCorrection bool
+
+	originalWidth  int
+	originalHeight int
+
+	stats *ImageMetrics
+
+	leastDistortion bool
+
+	outputDir string
+	overwrite OverwritePolicy
+
+	include []string
+	exclude []string
+
+	codec     c.Codec
+	autoCodec bool
+
+	// ctx is set by the Context variants of the handler methods
+	ctx      context.Context
+	progress func(done, total int64)
+}
+
+// monitor returns the monitor checking the context and reporting progress, nil if neither is set.
+func (o *options) monitor() *u.Monitor {
+	if o.ctx == nil && o.progress == nil {
+		return nil
+	}
+
+	return u.NewMonitor(o.ctx, o.progress)
+}
+
+func applyOptions(opts []Option) *options {
+	o := &options{
+		dataShards:   DefaultDataShards,
+		parityShards: DefaultParityShards,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+
+	return o
+}
+
+// WithNoiseFill pads the capacity left after the payload with CSPRNG bits at the chosen bit depth,
+// so the payload length cannot be observed from the image.
+func WithNoiseFill() Option {
+	return func(o *options) {
+		o.noiseFill = true
+		o.noiseKey = nil
+	}
+}
+
+// WithKeyedNoiseFill is like WithNoiseFill but uses a keystream derived from key and the cover,
+// making the output reproducible for the same key, cover and payload. Different covers get different noise.
+func WithKeyedNoiseFill(key []byte) Option {
+	return func(o *options) {
+		o.noiseFill = true
+		o.noiseKey = key
+	}
+}
+
+// WithReedSolomon sets the number of Reed-Solomon data and parity shards used by SecureEmbedHandler.
+// Up to parityShards corrupt shards can be recovered on extraction.
+func WithReedSolomon(dataShards, parityShards int) Option {
+	return func(o *options) {
+		o.dataShards = dataShards
+		o.parityShards = parityShards
+	}
+}
+
+// WithErrorCorrection protects the embedded data with interleaved RS(255,223) codewords spread over the whole image,
+// correcting scattered bit errors and damaged regions on extraction without knowing where they are.
+// The same option must be passed to the matching Decode call, DecodeAuto reads it from the image.
+func WithErrorCorrection() Option {
+	return func(o *options) {
+		o.errorCorrection = true
+	}
+}
+
+// WithOriginalSize tells watermark detection the dimensions of the image at embedding time,
+// so rescaled copies are resampled back before detection.
+func WithOriginalSize(width, height int) Option {
+	return func(o *options) {
+		o.originalWidth = width
+		o.originalHeight = height
+	}
+}
+
+// WithStats fills stats with quality metrics of the stego image against the cover once embedding succeeds.
+func WithStats(stats *ImageMetrics) Option {
+	return func(o *options) {
+		o.stats = stats
+	}
+}
+
+// WithLeastDistortion makes EncodeAuto place the data in the channels with the lowest embedding cost first,
+// like EmbedAdaptive. The mode is recorded in the header, DecodeAuto does not need the option.
+func WithLeastDistortion() Option {
+	return func(o *options) {
+		o.leastDistortion = true
+	}
+}
+
+// WithOutputDir sets the directory ExtractFile writes to instead of the current directory.
+func WithOutputDir(dir string) Option {
+	return func(o *options) {
+		o.outputDir = dir
+	}
+}
+
+// WithOverwritePolicy sets what ExtractFile does with files that already exist, OverwriteFail by default.
+func WithOverwritePolicy(policy OverwritePolicy) Option {
+	return func(o *options) {
+		o.overwrite = policy
+	}
+}
+
+// WithInclude limits EmbedDirectory and ExtractDirectory to the files matching one of the glob patterns.
+func WithInclude(patterns ...string) Option {
+	return func(o *options) {
+		o.include = append(o.include, patterns...)
+	}
+}
+
+// WithExclude makes EmbedDirectory and ExtractDirectory skip the files and directories matching one of the glob patterns.
+func WithExclude(patterns ...string) Option {
+	return func(o *options) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
+
+// WithCodec sets the codec used when data is compressed, zstd at the default level otherwise.
+// The codec ID is stored with the data, so decoding needs no option as long as the codec is registered.
+func WithCodec(codec c.Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+		o.autoCodec = false
+	}
+}
+
+// WithAutoCodec compresses the data with every registered codec and keeps the smallest output.
+func WithAutoCodec() Option {
+	return func(o *options) {
+		o.autoCodec = true
+	}
+}
+
+// WithProgress calls fn with the number of bits processed so far and the total, from the goroutine running
+// the method, every few ten thousand bits and once at the end. It is honoured by Encode, Decode, EncodeAuto,
+// DecodeAuto, EmbedStream and ExtractStream of the image handlers, EmbedIntoWAVWithDepth, ExtractFromWAVWithDepth,
+// their Context variants, EmbedDataIntoImage and EmbedDirectory. Other methods accept it but do not report progress.
+func WithProgress(fn func(done, total int64)) Option {
+	return func(o *options) {
+		o.progress = fn
+	}
+}
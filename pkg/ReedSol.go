@@ -1,7 +1,11 @@
 package pkg
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"hash/crc32"
+
 	"github.com/klauspost/reedsolomon"
 )
 
@@ -132,3 +136,176 @@ func RsDecode(packedShards []byte, dataShards, parityShards int) ([]byte, error)
 
 	return shards[0], nil
 }
+
+// The shard format starts with a header holding a magic byte, the format version, data shards (1 byte),
+// parity shards (1 byte), data length (4 bytes), shard size (4 bytes) and a CRC32 over these fields.
+// The header is stored rsHeaderCopies times so a damaged copy can be outvoted by the others.
+const (
+	rsShardMagic      byte = 0xB5
+	rsShardVersion    byte = 1
+	rsHeaderCopySize       = 16
+	rsHeaderCopies         = 3
+	rsShardHeaderSize      = rsHeaderCopies * rsHeaderCopySize
+)
+
+var (
+	ErrNotShardFormat     = errors.New("data is not in the Reed-Solomon shard format")
+	ErrShardVersion       = errors.New("unsupported Reed-Solomon shard format version")
+	ErrCorruptShardHeader = errors.New("Reed-Solomon shard header is corrupt")
+)
+
+// RsEncodeShards splits data into dataShards shards, adds parityShards parity shards and packs them into a slice.
+// The shard geometry is stored in a redundant header and every shard is prefixed with a CRC32 checksum,
+// so corrupt shards can be treated as erasures on decoding.
+func RsEncodeShards(data []byte, dataShards, parityShards int) ([]byte, error) {
+	if dataShards <= 0 || parityShards <= 0 {
+		return nil, fmt.Errorf("data and parity shards must be greater than zero")
+	}
+
+	if dataShards > 255 || parityShards > 255 {
+		return nil, fmt.Errorf("data and parity shards must not exceed 255")
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("data cannot be empty")
+	}
+
+	enc, err := reedsolomon.New(dataShards, parityShards)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create encoder: %w", err)
+	}
+
+	shards, err := enc.Split(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split data: %w", err)
+	}
+
+	if err := enc.Encode(shards); err != nil {
+		return nil, fmt.Errorf("failed to encode shards: %w", err)
+	}
+
+	shardSize := len(shards[0])
+	header := make([]byte, 0, rsHeaderCopySize)
+	header = append(header, rsShardMagic, rsShardVersion, byte(dataShards), byte(parityShards))
+	header = append(header, intToArr(len(data))...)
+	header = append(header, intToArr(shardSize)...)
+	header = append(header, intToArr(int(crc32.ChecksumIEEE(header)))...)
+
+	packed := make([]byte, 0, rsShardHeaderSize+len(shards)*(4+shardSize))
+	for i := 0; i < rsHeaderCopies; i++ {
+		packed = append(packed, header...)
+	}
+
+	for _, shard := range shards {
+		packed = append(packed, intToArr(int(crc32.ChecksumIEEE(shard)))...)
+		packed = append(packed, shard...)
+	}
+
+	return packed, nil
+}
+
+// readShardHeader returns the first header copy with a valid checksum,
+// or the bytewise majority of the copies if none is intact.
+func readShardHeader(packed []byte) ([]byte, error) {
+	copies := make([][]byte, rsHeaderCopies)
+	for i := range copies {
+		copies[i] = packed[i*rsHeaderCopySize : (i+1)*rsHeaderCopySize]
+	}
+
+	valid := func(h []byte) bool {
+		checksum, _ := arrToInt(h[12:16])
+		return uint32(checksum) == crc32.ChecksumIEEE(h[:12])
+	}
+
+	for _, h := range copies {
+		if valid(h) {
+			return h, nil
+		}
+	}
+
+	header := make([]byte, rsHeaderCopySize)
+	for i := range header {
+		a, b, c := copies[0][i], copies[1][i], copies[2][i]
+		if a == b || a == c {
+			header[i] = a
+		} else {
+			header[i] = b
+		}
+	}
+
+	if header[0] != rsShardMagic {
+		return nil, ErrNotShardFormat
+	}
+
+	if !valid(header) {
+		return nil, ErrCorruptShardHeader
+	}
+
+	return header, nil
+}
+
+// RsDecodeShards unpacks a slice produced by RsEncodeShards, drops shards whose checksum does not match
+// and reconstructs the original data from the remaining shards.
+func RsDecodeShards(packed []byte) ([]byte, error) {
+	if len(packed) < rsShardHeaderSize {
+		return nil, fmt.Errorf("%w: too short for the shard header", ErrNotShardFormat)
+	}
+
+	header, err := readShardHeader(packed)
+	if err != nil {
+		return nil, err
+	}
+
+	if header[1] != rsShardVersion {
+		return nil, fmt.Errorf("%w: %d", ErrShardVersion, header[1])
+	}
+
+	dataShards, parityShards := int(header[2]), int(header[3])
+	dataLen, _ := arrToInt(header[4:8])
+	shardSize, _ := arrToInt(header[8:12])
+
+	if dataShards == 0 || parityShards == 0 || shardSize <= 0 || dataLen < 0 || dataLen > dataShards*shardSize {
+		return nil, fmt.Errorf("invalid shard geometry")
+	}
+
+	total := dataShards + parityShards
+	if expected := rsShardHeaderSize + total*(4+shardSize); len(packed) < expected {
+		return nil, fmt.Errorf("data size mismatch: expected at least %d bytes, got %d", expected, len(packed))
+	}
+
+	enc, err := reedsolomon.New(dataShards, parityShards)
+	if err != nil {
+		return nil, fmt.Errorf("error creating decoder: %w", err)
+	}
+
+	shards := make([][]byte, total)
+	missing := 0
+	offset := rsShardHeaderSize
+	for i := 0; i < total; i++ {
+		checksum, _ := arrToInt(packed[offset : offset+4])
+		shard := packed[offset+4 : offset+4+shardSize]
+		offset += 4 + shardSize
+
+		if uint32(checksum) != crc32.ChecksumIEEE(shard) {
+			missing++
+			continue
+		}
+
+		shards[i] = shard
+	}
+
+	if missing > parityShards {
+		return nil, fmt.Errorf("too many corrupt shards: %d corrupt, %d parity", missing, parityShards)
+	}
+
+	if err := enc.ReconstructData(shards); err != nil {
+		return nil, fmt.Errorf("error reconstructing shards: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := enc.Join(&out, shards, dataLen); err != nil {
+		return nil, fmt.Errorf("error joining shards: %w", err)
+	}
+
+	return out.Bytes(), nil
+}
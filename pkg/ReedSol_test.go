@@ -1,6 +1,9 @@
 package pkg
 
 import (
+	"bytes"
+	"errors"
+	"hash/crc32"
 	"reflect"
 	"testing"
 )
@@ -195,3 +198,138 @@ func TestRsDecode(t *testing.T) {
 		})
 	}
 }
+
+func TestRsEncodeDecodeShards(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+
+	tests := []struct {
+		name          string
+		dataShards    int
+		parityShards  int
+		corruptShards []int
+		wantErr       bool
+	}{
+		{
+			name:         "No corruption",
+			dataShards:   4,
+			parityShards: 2,
+		},
+		{
+			name:          "Corruption within parity",
+			dataShards:    4,
+			parityShards:  2,
+			corruptShards: []int{0, 3},
+		},
+		{
+			name:          "Corruption exceeds parity",
+			dataShards:    4,
+			parityShards:  2,
+			corruptShards: []int{0, 1, 5},
+			wantErr:       true,
+		},
+		{
+			name:          "Single data shard",
+			dataShards:    1,
+			parityShards:  3,
+			corruptShards: []int{0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			packed, err := RsEncodeShards(data, test.dataShards, test.parityShards)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if packed[0] != rsShardMagic || int(packed[2]) != test.dataShards || int(packed[3]) != test.parityShards {
+				t.Fatalf("shard geometry not stored in header: %v", packed[:4])
+			}
+
+			shardSize, _ := arrToInt(packed[8:12])
+			for _, idx := range test.corruptShards {
+				packed[rsShardHeaderSize+idx*(4+shardSize)+4] ^= 0xFF
+			}
+
+			res, err := RsDecodeShards(packed)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error %v, got %v", test.wantErr, err)
+			}
+
+			if !test.wantErr && !reflect.DeepEqual(res, data) {
+				t.Errorf("expected %v but got %v", data, res)
+			}
+		})
+	}
+}
+
+func TestRsEncodeShardsInvalidGeometry(t *testing.T) {
+	if _, err := RsEncodeShards([]byte("data"), 0, 2); err == nil {
+		t.Error("expected error for zero data shards")
+	}
+
+	if _, err := RsEncodeShards([]byte("data"), 4, 0); err == nil {
+		t.Error("expected error for zero parity shards")
+	}
+
+	if _, err := RsDecodeShards([]byte{1, 2, 3}); !errors.Is(err, ErrNotShardFormat) {
+		t.Errorf("expected error: %v, got: %v", ErrNotShardFormat, err)
+	}
+}
+
+func TestRsDecodeShardsHeader(t *testing.T) {
+	data := []byte("the header must survive a few flipped bits")
+
+	packed, err := RsEncodeShards(data, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt := func(positions ...int) []byte {
+		c := bytes.Clone(packed)
+		for _, p := range positions {
+			c[p] ^= 0x10
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		packed  []byte
+		wantErr error
+	}{
+		{name: "First copy damaged", packed: corrupt(2, 5)},
+		{name: "Every copy damaged at different bytes", packed: corrupt(2, rsHeaderCopySize+5, 2*rsHeaderCopySize+9)},
+		{name: "Only the last copy intact", packed: corrupt(9, rsHeaderCopySize+9)},
+		{name: "Same byte damaged in two copies", packed: corrupt(9, rsHeaderCopySize+9, 2*rsHeaderCopySize+3), wantErr: ErrCorruptShardHeader},
+		{name: "No magic", packed: corrupt(0, rsHeaderCopySize, 2*rsHeaderCopySize), wantErr: ErrNotShardFormat},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := RsDecodeShards(test.packed)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("expected error: %v, got: %v", test.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil || !bytes.Equal(res, data) {
+				t.Fatalf("expected %q, got %q, %v", data, res, err)
+			}
+		})
+	}
+
+	// a newer version must be rejected rather than misread
+	future := bytes.Clone(packed)
+	for i := 0; i < rsHeaderCopies; i++ {
+		h := future[i*rsHeaderCopySize : (i+1)*rsHeaderCopySize]
+		h[1]++
+		copy(h[12:], intToArr(int(crc32.ChecksumIEEE(h[:12]))))
+	}
+
+	if _, err := RsDecodeShards(future); !errors.Is(err, ErrShardVersion) {
+		t.Fatalf("expected error: %v, got: %v", ErrShardVersion, err)
+	}
+}
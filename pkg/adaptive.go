@@ -0,0 +1,152 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CostMap returns an embedding cost for every channel sample (pixel*3 + channel) of a width x height image.
+// The cost is the inverse of the local variance in the 3x3 neighbourhood, so textured areas are cheap and
+// smooth areas are expensive. Only the bits above depth are looked at, which embedding never changes,
+// so the extractor computes exactly the same map from the stego image.
+func CostMap(RGBchannels []RgbChannel, width, height int, depth uint8) ([]float64, error) {
+	if depth > 7 {
+		return nil, fmt.Errorf("bit depth exeeds 7")
+	}
+
+	if width <= 0 || height <= 0 || len(RGBchannels) != width*height {
+		return nil, fmt.Errorf("rgbchannels do not match image dimensions")
+	}
+
+	shift := depth + 1
+	costs := make([]float64, len(RGBchannels)*3)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			for c := 0; c < 3; c++ {
+				var sum, sumSq, n float64
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						nx, ny := x+dx, y+dy
+						if nx < 0 || ny < 0 || nx >= width || ny >= height {
+							continue
+						}
+
+						v := float64(getSample(RGBchannels, (ny*width+nx)*3+c) >> shift)
+						sum += v
+						sumSq += v * v
+						n++
+					}
+				}
+
+				mean := sum / n
+				costs[(y*width+x)*3+c] = 1 / (1 + sumSq/n - mean*mean)
+			}
+		}
+	}
+
+	return costs, nil
+}
+
+// AdaptiveOrder returns the sample indices sorted from lowest to highest cost. Ties keep their raster order.
+func AdaptiveOrder(costs []float64) []int {
+	order := make([]int, len(costs))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return costs[order[i]] < costs[order[j]]
+	})
+
+	return order
+}
+
+// EmbedIntoRGBchannelsWithOrder works like EmbedIntoRGBchannelsWithDepth but visits the samples in the given order
+// instead of sequentially, so the payload lands in the samples listed first.
+func EmbedIntoRGBchannelsWithOrder(RGBchannels []RgbChannel, data []byte, depth uint8, order []int) ([]RgbChannel, error) {
+	if depth > 7 {
+		return nil, fmt.Errorf("bit depth exeeds 7")
+	}
+
+	stream := append(Int32ToBinary(int32(len(data))), BytesToBinary(data)...)
+	if len(stream) > len(order)*(int(depth)+1) {
+		return nil, fmt.Errorf("data is too big")
+	}
+
+	return embedBitsWithOrder(RGBchannels, stream, depth, order, nil)
+}
+
+// embedBitsWithOrder writes stream into the last depth+1 bits of the samples in order, most significant of those bits first.
+func embedBitsWithOrder(RGBchannels []RgbChannel, stream []uint8, depth uint8, order []int, m *Monitor) ([]RgbChannel, error) {
+	total := int64(len(stream))
+	step := max(ProgressInterval/(int(depth)+1), 1)
+	idx := 0
+	for i, k := range order {
+		if i%step == 0 {
+			if err := m.Step(int64(idx), total); err != nil {
+				return nil, err
+			}
+		}
+
+		v := getSample(RGBchannels, k)
+		for bit := int(depth); bit >= 0 && idx < len(stream); bit-- {
+			if GetBit(v, uint8(bit)) != stream[idx] {
+				v = FlipBit(v, uint8(bit))
+			}
+			idx++
+		}
+		setSample(RGBchannels, k, v)
+
+		if idx >= len(stream) {
+			break
+		}
+	}
+
+	if err := m.Step(total, total); err != nil {
+		return nil, err
+	}
+
+	return RGBchannels, nil
+}
+
+// ExtractDataFromRGBchannelsWithOrder extracts data embedded with EmbedIntoRGBchannelsWithOrder.
+// Unlike ExtractDataFromRGBchannelsWithDepth the length prefix is already stripped.
+func ExtractDataFromRGBchannelsWithOrder(RGBchannels []RgbChannel, depth uint8, order []int) ([]byte, error) {
+	if depth > 7 {
+		return nil, fmt.Errorf("bit depth exeeds 7")
+	}
+
+	var stream []uint8
+	need := 32
+	for _, k := range order {
+		v := getSample(RGBchannels, k)
+		for bit := int(depth); bit >= 0; bit-- {
+			stream = append(stream, GetBit(v, uint8(bit)))
+		}
+
+		if need == 32 && len(stream) >= 32 {
+			need = 32 + bitsToInt(stream[:32])*8
+		}
+
+		if len(stream) >= need {
+			break
+		}
+	}
+
+	if len(stream) < 32 {
+		return nil, fmt.Errorf("insufficient data: expected at least 32 bits")
+	}
+
+	lenData := bitsToInt(stream[:32])
+	if lenData <= 0 || len(stream) < 32+lenData*8 {
+		return nil, fmt.Errorf("invalid data length %d", lenData)
+	}
+
+	data := make([]byte, lenData)
+	for i := range data {
+		data[i] = byte(bitsToInt(stream[32+i*8 : 32+(i+1)*8]))
+	}
+
+	return data, nil
+}
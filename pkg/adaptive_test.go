@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCostMapPrefersTexture(t *testing.T) {
+	width, height := 16, 16
+	channels := make([]RgbChannel, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint32(100)
+			if x >= 8 && (x+y)%2 == 0 {
+				v = 200
+			}
+			channels[y*width+x] = RgbChannel{R: v, G: v, B: v}
+		}
+	}
+
+	costs, err := CostMap(channels, width, height, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if costs[(4*width+2)*3] <= costs[(4*width+12)*3] {
+		t.Fatal("expected smooth samples to cost more than textured ones")
+	}
+
+	order := AdaptiveOrder(costs)
+	if x := (order[0] / 3) % width; x < 8 {
+		t.Fatalf("expected the cheapest sample in the textured half, got x=%d", x)
+	}
+}
+
+func TestEmbedExtractWithOrder(t *testing.T) {
+	width, height := 32, 32
+	data := []byte("adaptive embedding")
+
+	for _, depth := range []uint8{0, 2} {
+		channels := createTexturedChannels(width, height)
+
+		costs, err := CostMap(channels, width, height, depth)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stego, err := EmbedIntoRGBchannelsWithOrder(channels, data, depth, AdaptiveOrder(costs))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the extractor recomputes the order from the stego image
+		stegoCosts, err := CostMap(stego, width, height, depth)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(costs, stegoCosts) {
+			t.Fatal("cost map changed by embedding")
+		}
+
+		extracted, err := ExtractDataFromRGBchannelsWithOrder(stego, depth, AdaptiveOrder(stegoCosts))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(extracted, data) {
+			t.Fatalf("depth %d: expected %q, got %q", depth, data, extracted)
+		}
+	}
+
+	if _, err := EmbedIntoRGBchannelsWithOrder(createTexturedChannels(2, 2), data, 0, []int{0, 1, 2}); err == nil {
+		t.Fatal("expected error for data exceeding the capacity")
+	}
+}
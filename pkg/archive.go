@@ -0,0 +1,124 @@
+package pkg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// Archive format: the magic "SGA1" and a 4 byte entry count, followed by the entries. Every entry holds
+// a 2 byte name length, the name, the 4 byte file mode, the modification time as 8 byte unix nanoseconds,
+// the 8 byte size, the SHA-256 of the contents and the contents themselves. All integers are big endian.
+var archiveMagic = []byte("SGA1")
+
+const archiveEntryHeaderSize = 2 + 4 + 8 + 8 + sha256.Size
+
+var ErrInvalidArchive = errors.New("data is not a valid archive")
+
+// ArchiveEntry is a file stored in an archive.
+type ArchiveEntry struct {
+	Name    string
+	Mode    fs.FileMode
+	ModTime time.Time
+	Size    int64
+	SHA256  [sha256.Size]byte
+	Data    []byte
+}
+
+// NewArchiveEntry creates an entry for data, filling in its size and hash.
+func NewArchiveEntry(name string, mode fs.FileMode, modTime time.Time, data []byte) ArchiveEntry {
+	return ArchiveEntry{
+		Name:    name,
+		Mode:    mode,
+		ModTime: modTime,
+		Size:    int64(len(data)),
+		SHA256:  sha256.Sum256(data),
+		Data:    data,
+	}
+}
+
+// MarshalArchive encodes entries into a single archive.
+func MarshalArchive(entries []ArchiveEntry) ([]byte, error) {
+	size := len(archiveMagic) + 4
+	for _, e := range entries {
+		if e.Name == "" || len(e.Name) > 0xFFFF {
+			return nil, fmt.Errorf("invalid entry name %q", e.Name)
+		}
+		size += archiveEntryHeaderSize + len(e.Name) + len(e.Data)
+	}
+
+	out := make([]byte, 0, size)
+	out = append(out, archiveMagic...)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(entries)))
+
+	for _, e := range entries {
+		out = binary.BigEndian.AppendUint16(out, uint16(len(e.Name)))
+		out = append(out, e.Name...)
+		out = binary.BigEndian.AppendUint32(out, uint32(e.Mode))
+		out = binary.BigEndian.AppendUint64(out, uint64(e.ModTime.UnixNano()))
+		out = binary.BigEndian.AppendUint64(out, uint64(len(e.Data)))
+		hash := sha256.Sum256(e.Data)
+		out = append(out, hash[:]...)
+		out = append(out, e.Data...)
+	}
+
+	return out, nil
+}
+
+// UnmarshalArchive decodes an archive produced by MarshalArchive and verifies the hash of every entry.
+// The returned entries share memory with data.
+func UnmarshalArchive(data []byte) ([]ArchiveEntry, error) {
+	if len(data) < len(archiveMagic)+4 || !bytes.Equal(data[:len(archiveMagic)], archiveMagic) {
+		return nil, ErrInvalidArchive
+	}
+
+	count := binary.BigEndian.Uint32(data[len(archiveMagic):])
+	pos := len(archiveMagic) + 4
+
+	// every entry takes at least its header, which bounds the allocation for corrupt counts
+	if uint64(count)*archiveEntryHeaderSize > uint64(len(data)-pos) {
+		return nil, ErrInvalidArchive
+	}
+
+	entries := make([]ArchiveEntry, 0, count)
+	for i := uint32(0); i < count; i++ {
+		if len(data)-pos < 2 {
+			return nil, ErrInvalidArchive
+		}
+
+		nameLen := int(binary.BigEndian.Uint16(data[pos:]))
+		pos += 2
+		if len(data)-pos < nameLen+archiveEntryHeaderSize-2 {
+			return nil, ErrInvalidArchive
+		}
+
+		var e ArchiveEntry
+		e.Name = string(data[pos : pos+nameLen])
+		pos += nameLen
+		e.Mode = fs.FileMode(binary.BigEndian.Uint32(data[pos:]))
+		e.ModTime = time.Unix(0, int64(binary.BigEndian.Uint64(data[pos+4:])))
+		size := binary.BigEndian.Uint64(data[pos+12:])
+		copy(e.SHA256[:], data[pos+20:pos+20+sha256.Size])
+		pos += 20 + sha256.Size
+
+		if size > uint64(len(data)-pos) {
+			return nil, ErrInvalidArchive
+		}
+
+		e.Size = int64(size)
+		e.Data = data[pos : pos+int(size)]
+		pos += int(size)
+
+		if sha256.Sum256(e.Data) != e.SHA256 {
+			return nil, fmt.Errorf("hash mismatch for entry %q: %w", e.Name, ErrInvalidArchive)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMarshalUnmarshalArchive(t *testing.T) {
+	modTime := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	entries := []ArchiveEntry{
+		NewArchiveEntry("notes.txt", 0o644, modTime, []byte("some notes")),
+		NewArchiveEntry("empty", 0o600, modTime, []byte{}),
+		NewArchiveEntry("script.sh", 0o755, modTime, []byte("#!/bin/sh\necho hi\n")),
+	}
+
+	archive, err := MarshalArchive(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := UnmarshalArchive(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != len(entries) {
+		t.Fatalf("expected %d entries, got %d", len(entries), len(res))
+	}
+
+	for i := range entries {
+		if !res[i].ModTime.Equal(entries[i].ModTime) {
+			t.Fatalf("entry %d: expected mod time %v, got %v", i, entries[i].ModTime, res[i].ModTime)
+		}
+		res[i].ModTime = entries[i].ModTime
+
+		if !reflect.DeepEqual(res[i], entries[i]) {
+			t.Fatalf("entry %d: expected %+v, got %+v", i, entries[i], res[i])
+		}
+	}
+}
+
+func TestUnmarshalArchiveInvalid(t *testing.T) {
+	archive, err := MarshalArchive([]ArchiveEntry{NewArchiveEntry("a", 0o644, time.Now(), []byte("contents"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Bad magic", data: append([]byte("XXXX"), archive[4:]...)},
+		{name: "Truncated", data: archive[:len(archive)-3]},
+		{name: "Corrupt contents", data: append(append([]byte(nil), archive[:len(archive)-1]...), archive[len(archive)-1]^1)},
+		{name: "Huge count", data: append([]byte("SGA1\xff\xff\xff\xff"), archive[8:]...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := UnmarshalArchive(tt.data); !errors.Is(err, ErrInvalidArchive) {
+				t.Fatalf("expected %v, got %v", ErrInvalidArchive, err)
+			}
+		})
+	}
+
+	if _, err := MarshalArchive([]ArchiveEntry{{Name: ""}}); err == nil {
+		t.Fatal("expected error for empty name")
+	}
+}
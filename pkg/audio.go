@@ -42,6 +42,12 @@ func EmbedDataAtDepthAudio(buffer *audio.IntBuffer, data []byte, depth uint8) (*
 }
 
 func EmbedDataWithDepthAudio(buffer *audio.IntBuffer, data []byte, bitDepth uint8) (*audio.IntBuffer, error) {
+	return EmbedDataWithDepthAudioMonitored(buffer, data, bitDepth, nil)
+}
+
+// EmbedDataWithDepthAudioMonitored works like EmbedDataWithDepthAudio, reporting to m
+// and stopping with the context's error once it is cancelled.
+func EmbedDataWithDepthAudioMonitored(buffer *audio.IntBuffer, data []byte, bitDepth uint8, m *Monitor) (*audio.IntBuffer, error) {
 	if len(data) == 0 {
 		return nil, ErrDataIsEmpty
 	}
@@ -65,7 +71,14 @@ func EmbedDataWithDepthAudio(buffer *audio.IntBuffer, data []byte, bitDepth uint
 	curbit := bitDepth
 	index := 0
 
+	total := int64(len(lenBits))
 	for i := 0; i < len(lenBits); i++ {
+		if i%ProgressInterval == 0 {
+			if err := m.Step(int64(i), total); err != nil {
+				return nil, err
+			}
+		}
+
 		if lenBits[i] != GetBit(uint32(buffer.Data[index]), curbit) {
 			buffer.Data[index] = int(FlipBit(uint32(buffer.Data[index]), curbit))
 		}
@@ -78,6 +91,10 @@ func EmbedDataWithDepthAudio(buffer *audio.IntBuffer, data []byte, bitDepth uint
 		}
 	}
 
+	if err := m.Step(total, total); err != nil {
+		return nil, err
+	}
+
 	return buffer, nil
 }
 
@@ -102,11 +119,27 @@ func ExtractDataAtDepthAudio(buffer *audio.IntBuffer, depth uint8) []byte {
 }
 
 func ExtractDataWithDepthAudio(buffer *audio.IntBuffer, depth uint8) []byte {
+	data, _ := ExtractDataWithDepthAudioMonitored(buffer, depth, nil)
+	return data
+}
+
+// ExtractDataWithDepthAudioMonitored works like ExtractDataWithDepthAudio, reporting to m
+// and stopping with the context's error once it is cancelled.
+func ExtractDataWithDepthAudioMonitored(buffer *audio.IntBuffer, depth uint8, m *Monitor) ([]byte, error) {
 	var byteSlice = make([]byte, 0)
 	var currentByte uint8 = 0
 	bitCount := 0
 
+	perSample := int(depth) + 1
+	total := int64(len(buffer.Data) * perSample)
+	step := max(ProgressInterval/perSample, 1)
 	for i := 0; i < len(buffer.Data); i++ {
+		if i%step == 0 {
+			if err := m.Step(int64(i*perSample), total); err != nil {
+				return nil, err
+			}
+		}
+
 		for bd := depth + 1; bd > 0; bd-- {
 			r := GetBit(uint32(buffer.Data[i]), bd-1)
 			currentByte = (currentByte << 1) | (r & 1)
@@ -125,5 +158,9 @@ func ExtractDataWithDepthAudio(buffer *audio.IntBuffer, depth uint8) []byte {
 		byteSlice = append(byteSlice, currentByte)
 	}
 
-	return byteSlice
+	if err := m.Step(total, total); err != nil {
+		return nil, err
+	}
+
+	return byteSlice, nil
 }
@@ -0,0 +1,130 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AutoHeaderSamples is the number of leading channel samples whose LSBs hold the auto depth header:
+// an 8 bit magic, the bit depth (3 bits), the embedding mode (1 bit), the error correction flag (1 bit)
+// and 3 reserved zero bits.
+const AutoHeaderSamples = 16
+
+const autoMagic = 0xA5
+
+var ErrNoAutoHeader = errors.New("no auto depth header found")
+
+// EmbedAutoHeader writes the auto depth header into the LSBs of the first AutoHeaderSamples samples.
+func EmbedAutoHeader(RGBchannels []RgbChannel, depth uint8, adaptive, ecc bool) error {
+	if depth > 7 {
+		return fmt.Errorf("bit depth exeeds 7")
+	}
+
+	if len(RGBchannels)*3 < AutoHeaderSamples {
+		return fmt.Errorf("image is too small for the auto depth header")
+	}
+
+	header := uint32(autoMagic)<<8 | uint32(depth)<<5
+	if adaptive {
+		header |= 1 << 4
+	}
+
+	if ecc {
+		header |= 1 << 3
+	}
+
+	for k := 0; k < AutoHeaderSamples; k++ {
+		bit := uint8(header >> (AutoHeaderSamples - 1 - k) & 1)
+		v := getSample(RGBchannels, k)
+		if GetBit(v, 0) != bit {
+			setSample(RGBchannels, k, FlipBit(v, 0))
+		}
+	}
+
+	return nil
+}
+
+// ExtractAutoHeader reads the header written by EmbedAutoHeader.
+func ExtractAutoHeader(RGBchannels []RgbChannel) (depth uint8, adaptive, ecc bool, err error) {
+	if len(RGBchannels)*3 < AutoHeaderSamples {
+		return 0, false, false, ErrNoAutoHeader
+	}
+
+	var header uint32
+	for k := 0; k < AutoHeaderSamples; k++ {
+		header = header<<1 | uint32(GetBit(getSample(RGBchannels, k), 0))
+	}
+
+	if header>>8 != autoMagic || header&7 != 0 {
+		return 0, false, false, ErrNoAutoHeader
+	}
+
+	return uint8(header >> 5 & 7), header>>4&1 == 1, header>>3&1 == 1, nil
+}
+
+// AutoOrder returns the samples after the auto depth header in raster order.
+func AutoOrder(RGBchannels []RgbChannel) []int {
+	order := make([]int, 0, max(len(RGBchannels)*3-AutoHeaderSamples, 0))
+	for k := AutoHeaderSamples; k < len(RGBchannels)*3; k++ {
+		order = append(order, k)
+	}
+
+	return order
+}
+
+// EmbedRawIntoRGBchannelsWithOrder writes data without a length prefix into the last depth+1 bits of the samples in order.
+func EmbedRawIntoRGBchannelsWithOrder(RGBchannels []RgbChannel, data []byte, depth uint8, order []int) ([]RgbChannel, error) {
+	return EmbedRawIntoRGBchannelsWithOrderMonitored(RGBchannels, data, depth, order, nil)
+}
+
+// EmbedRawIntoRGBchannelsWithOrderMonitored works like EmbedRawIntoRGBchannelsWithOrder, reporting to m
+// and stopping with the context's error once it is cancelled.
+func EmbedRawIntoRGBchannelsWithOrderMonitored(RGBchannels []RgbChannel, data []byte, depth uint8, order []int, m *Monitor) ([]RgbChannel, error) {
+	if depth > 7 {
+		return nil, fmt.Errorf("bit depth exeeds 7")
+	}
+
+	stream := BytesToBinary(data)
+	if len(stream) > len(order)*(int(depth)+1) {
+		return nil, fmt.Errorf("data is too big")
+	}
+
+	return embedBitsWithOrder(RGBchannels, stream, depth, order, m)
+}
+
+// ExtractRawFromRGBchannelsWithOrder reads every whole byte stored in the last depth+1 bits of the samples in order.
+func ExtractRawFromRGBchannelsWithOrder(RGBchannels []RgbChannel, depth uint8, order []int) ([]byte, error) {
+	return ExtractRawFromRGBchannelsWithOrderMonitored(RGBchannels, depth, order, nil)
+}
+
+// ExtractRawFromRGBchannelsWithOrderMonitored works like ExtractRawFromRGBchannelsWithOrder, reporting to m
+// and stopping with the context's error once it is cancelled.
+func ExtractRawFromRGBchannelsWithOrderMonitored(RGBchannels []RgbChannel, depth uint8, order []int, m *Monitor) ([]byte, error) {
+	if depth > 7 {
+		return nil, fmt.Errorf("bit depth exeeds 7")
+	}
+
+	data := make([]byte, len(order)*(int(depth)+1)/8)
+	total := int64(len(data) * 8)
+	step := max(ProgressInterval/(int(depth)+1), 1)
+	idx := 0
+	for i, k := range order {
+		if i%step == 0 {
+			if err := m.Step(int64(idx), total); err != nil {
+				return nil, err
+			}
+		}
+
+		v := getSample(RGBchannels, k)
+		for bit := int(depth); bit >= 0 && idx < len(data)*8; bit-- {
+			data[idx/8] |= GetBit(v, uint8(bit)) << (7 - idx%8)
+			idx++
+		}
+	}
+
+	if err := m.Step(total, total); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
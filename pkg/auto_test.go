@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAutoHeader(t *testing.T) {
+	channels := createTexturedChannels(8, 8)
+
+	if _, _, _, err := ExtractAutoHeader(channels); err != ErrNoAutoHeader {
+		t.Fatalf("expected %v on a clean image, got %v", ErrNoAutoHeader, err)
+	}
+
+	for _, tt := range []struct {
+		depth    uint8
+		adaptive bool
+		ecc      bool
+	}{{0, false, false}, {3, true, false}, {7, false, true}, {2, true, true}} {
+		if err := EmbedAutoHeader(channels, tt.depth, tt.adaptive, tt.ecc); err != nil {
+			t.Fatal(err)
+		}
+
+		depth, adaptive, ecc, err := ExtractAutoHeader(channels)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if depth != tt.depth || adaptive != tt.adaptive || ecc != tt.ecc {
+			t.Fatalf("expected depth %d adaptive %v ecc %v, got %d %v %v", tt.depth, tt.adaptive, tt.ecc, depth, adaptive, ecc)
+		}
+	}
+
+	if err := EmbedAutoHeader(channels[:5], 0, false, false); err == nil {
+		t.Fatal("expected error for image smaller than the header")
+	}
+}
+
+func TestRawWithOrder(t *testing.T) {
+	channels := createTexturedChannels(16, 16)
+	data := []byte("raw ordered payload")
+	order := AutoOrder(channels)
+
+	if len(order) != 16*16*3-AutoHeaderSamples || order[0] != AutoHeaderSamples {
+		t.Fatalf("unexpected order of length %d starting at %d", len(order), order[0])
+	}
+
+	embedded, err := EmbedRawIntoRGBchannelsWithOrder(channels, data, 2, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extracted, err := ExtractRawFromRGBchannelsWithOrder(embedded, 2, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(extracted) != len(order)*3/8 || !bytes.Equal(extracted[:len(data)], data) {
+		t.Fatalf("unexpected extracted data %q", extracted[:len(data)])
+	}
+
+	if _, err := EmbedRawIntoRGBchannelsWithOrder(channels, make([]byte, 1000), 0, order); err == nil {
+		t.Fatal("expected error for data exceeding the capacity")
+	}
+}
@@ -0,0 +1,65 @@
+package pkg
+
+import "math"
+
+var dctCos [8][8]float64
+
+func init() {
+	for x := 0; x < 8; x++ {
+		for u := 0; u < 8; u++ {
+			c := math.Sqrt(2.0 / 8)
+			if u == 0 {
+				c = math.Sqrt(1.0 / 8)
+			}
+			dctCos[u][x] = c * math.Cos(float64(2*x+1)*float64(u)*math.Pi/16)
+		}
+	}
+}
+
+// DCT8x8 applies an orthonormal 2D DCT-II to a row major 8x8 block.
+func DCT8x8(block *[64]float64) {
+	var tmp [64]float64
+	for y := 0; y < 8; y++ {
+		for u := 0; u < 8; u++ {
+			var sum float64
+			for x := 0; x < 8; x++ {
+				sum += dctCos[u][x] * block[y*8+x]
+			}
+			tmp[y*8+u] = sum
+		}
+	}
+
+	for u := 0; u < 8; u++ {
+		for v := 0; v < 8; v++ {
+			var sum float64
+			for y := 0; y < 8; y++ {
+				sum += dctCos[v][y] * tmp[y*8+u]
+			}
+			block[v*8+u] = sum
+		}
+	}
+}
+
+// IDCT8x8 reverses DCT8x8.
+func IDCT8x8(block *[64]float64) {
+	var tmp [64]float64
+	for v := 0; v < 8; v++ {
+		for x := 0; x < 8; x++ {
+			var sum float64
+			for u := 0; u < 8; u++ {
+				sum += dctCos[u][x] * block[v*8+u]
+			}
+			tmp[v*8+x] = sum
+		}
+	}
+
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			var sum float64
+			for v := 0; v < 8; v++ {
+				sum += dctCos[v][y] * tmp[v*8+x]
+			}
+			block[y*8+x] = sum
+		}
+	}
+}
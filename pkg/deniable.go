@@ -0,0 +1,106 @@
+package pkg
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// SlotOverhead is the number of bytes a sealed slot spends on salt, nonce, GCM tag and the length prefix.
+const SlotOverhead = 16 + 12 + 16 + 4
+
+var ErrSlotTooSmall = errors.New("data exceeds the capacity of the slot")
+var ErrSlotNotFound = errors.New("no slot could be opened with the given password")
+
+// SealSlot encrypts data into a buffer of exactly slotSize bytes.
+// The length of the data is encrypted together with the data and the rest of the slot is
+// zero padded before encryption, so the whole slot is indistinguishable from random bytes.
+func SealSlot(password string, data []byte, slotSize int) ([]byte, error) {
+	if len(data)+SlotOverhead > slotSize {
+		return nil, ErrSlotTooSmall
+	}
+
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	key, err := deriveKey([]byte(password), salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aesGCM.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, slotSize-16-12-16)
+	copy(plaintext, intToArr(len(data)))
+	copy(plaintext[4:], data)
+
+	slot := make([]byte, 0, slotSize)
+	slot = append(slot, salt...)
+	slot = append(slot, nonce...)
+	return aesGCM.Seal(slot, nonce, plaintext, nil), nil
+}
+
+// OpenSlot decrypts a slot produced by SealSlot and returns the data stored in it.
+func OpenSlot(password string, slot []byte) ([]byte, error) {
+	if len(slot) < SlotOverhead {
+		return nil, ErrSlotNotFound
+	}
+
+	key, err := deriveKey([]byte(password), slot[:16])
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aesGCM.Open(nil, slot[16:28], slot[28:], nil)
+	if err != nil {
+		return nil, ErrSlotNotFound
+	}
+
+	lenData, err := arrToInt(plaintext[:4])
+	if err != nil {
+		return nil, err
+	}
+
+	if lenData < 0 || lenData > len(plaintext)-4 {
+		return nil, ErrSlotNotFound
+	}
+
+	return plaintext[4 : 4+lenData], nil
+}
+
+// RandomSlot returns slotSize random bytes, used to fill slots that carry no payload.
+func RandomSlot(slotSize int) ([]byte, error) {
+	slot := make([]byte, slotSize)
+	if _, err := io.ReadFull(rand.Reader, slot); err != nil {
+		return nil, err
+	}
+
+	return slot, nil
+}
@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSealOpenSlot(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		slotSize int
+	}{
+		{
+			name:     "Small payload",
+			data:     []byte("decoy message"),
+			slotSize: 256,
+		},
+		{
+			name:     "Payload fills slot",
+			data:     bytes.Repeat([]byte{0xAA}, 100),
+			slotSize: 100 + SlotOverhead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slot, err := SealSlot("password123", tt.data, tt.slotSize)
+			if err != nil {
+				t.Fatalf("SealSlot failed: %v", err)
+			}
+
+			if len(slot) != tt.slotSize {
+				t.Fatalf("expected slot of %d bytes, got %d", tt.slotSize, len(slot))
+			}
+
+			data, err := OpenSlot("password123", slot)
+			if err != nil {
+				t.Fatalf("OpenSlot failed: %v", err)
+			}
+
+			if !bytes.Equal(data, tt.data) {
+				t.Errorf("expected %v, got %v", tt.data, data)
+			}
+		})
+	}
+}
+
+func TestOpenSlotWrongPassword(t *testing.T) {
+	slot, err := SealSlot("password123", []byte("secret"), 128)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenSlot("wrong", slot); !errors.Is(err, ErrSlotNotFound) {
+		t.Fatalf("expected %v, got %v", ErrSlotNotFound, err)
+	}
+
+	random, err := RandomSlot(128)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenSlot("password123", random); !errors.Is(err, ErrSlotNotFound) {
+		t.Fatalf("expected %v, got %v", ErrSlotNotFound, err)
+	}
+}
+
+func TestSealSlotTooSmall(t *testing.T) {
+	if _, err := SealSlot("password123", []byte("secret"), SlotOverhead+5); !errors.Is(err, ErrSlotTooSmall) {
+		t.Fatalf("expected %v, got %v", ErrSlotTooSmall, err)
+	}
+}
@@ -0,0 +1,403 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Byte level Reed-Solomon code over GF(256). Unlike RsEncode, which can only rebuild shards that are known
+// to be missing, these codewords locate and correct scattered byte errors without any erasure hints.
+const (
+	ECCBlockSize = 255
+	ECCParity    = 32
+	ECCDataSize  = ECCBlockSize - ECCParity
+)
+
+// eccHeaderSize is the size of the shortened codeword holding the payload length.
+const eccHeaderSize = 4 + ECCParity
+
+var ErrTooManyErrors = errors.New("too many errors to correct")
+
+var (
+	gfExp [512]byte
+	gfLog [256]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+
+	for i := 255; i < 512; i++ {
+		gfExp[i] = gfExp[i-255]
+	}
+}
+
+func gfMul(x, y byte) byte {
+	if x == 0 || y == 0 {
+		return 0
+	}
+
+	return gfExp[int(gfLog[x])+int(gfLog[y])]
+}
+
+func gfDiv(x, y byte) byte {
+	if x == 0 {
+		return 0
+	}
+
+	return gfExp[(int(gfLog[x])+255-int(gfLog[y]))%255]
+}
+
+func gfPow(x byte, power int) byte {
+	p := (int(gfLog[x]) * power) % 255
+	if p < 0 {
+		p += 255
+	}
+
+	return gfExp[p]
+}
+
+func gfInverse(x byte) byte {
+	return gfExp[255-int(gfLog[x])]
+}
+
+func gfPolyScale(p []byte, x byte) []byte {
+	r := make([]byte, len(p))
+	for i := range p {
+		r[i] = gfMul(p[i], x)
+	}
+
+	return r
+}
+
+func gfPolyAdd(p, q []byte) []byte {
+	n := max(len(p), len(q))
+	r := make([]byte, n)
+	for i := range p {
+		r[i+n-len(p)] = p[i]
+	}
+
+	for i := range q {
+		r[i+n-len(q)] ^= q[i]
+	}
+
+	return r
+}
+
+func gfPolyMul(p, q []byte) []byte {
+	r := make([]byte, len(p)+len(q)-1)
+	for j := range q {
+		for i := range p {
+			r[i+j] ^= gfMul(p[i], q[j])
+		}
+	}
+
+	return r
+}
+
+func gfPolyEval(p []byte, x byte) byte {
+	y := p[0]
+	for i := 1; i < len(p); i++ {
+		y = gfMul(y, x) ^ p[i]
+	}
+
+	return y
+}
+
+// gfPolyRemainder returns the remainder of dividend / divisor, divisor must be monic.
+func gfPolyRemainder(dividend, divisor []byte) []byte {
+	out := append([]byte(nil), dividend...)
+	for i := 0; i < len(dividend)-(len(divisor)-1); i++ {
+		coef := out[i]
+		if coef == 0 {
+			continue
+		}
+
+		for j := 1; j < len(divisor); j++ {
+			if divisor[j] != 0 {
+				out[i+j] ^= gfMul(divisor[j], coef)
+			}
+		}
+	}
+
+	return out[len(out)-(len(divisor)-1):]
+}
+
+func rsGeneratorPoly(nsym int) []byte {
+	g := []byte{1}
+	for i := 0; i < nsym; i++ {
+		g = gfPolyMul(g, []byte{1, gfPow(2, i)})
+	}
+
+	return g
+}
+
+// rsEncodeMsg appends nsym parity bytes to msg.
+func rsEncodeMsg(msg []byte, nsym int) []byte {
+	gen := rsGeneratorPoly(nsym)
+	out := make([]byte, len(msg)+nsym)
+	copy(out, msg)
+
+	for i := 0; i < len(msg); i++ {
+		coef := out[i]
+		if coef == 0 {
+			continue
+		}
+
+		for j := 1; j < len(gen); j++ {
+			out[i+j] ^= gfMul(gen[j], coef)
+		}
+	}
+
+	copy(out, msg)
+	return out
+}
+
+func rsCalcSyndromes(msg []byte, nsym int) ([]byte, bool) {
+	synd := make([]byte, nsym+1)
+	clean := true
+	for i := 0; i < nsym; i++ {
+		synd[i+1] = gfPolyEval(msg, gfPow(2, i))
+		if synd[i+1] != 0 {
+			clean = false
+		}
+	}
+
+	return synd, clean
+}
+
+// rsFindErrorLocator runs Berlekamp-Massey over the syndromes.
+func rsFindErrorLocator(synd []byte, nsym int) ([]byte, error) {
+	errLoc := []byte{1}
+	oldLoc := []byte{1}
+	shift := len(synd) - nsym
+
+	for i := 0; i < nsym; i++ {
+		k := i + shift
+		delta := synd[k]
+		for j := 1; j < len(errLoc); j++ {
+			delta ^= gfMul(errLoc[len(errLoc)-1-j], synd[k-j])
+		}
+
+		oldLoc = append(oldLoc, 0)
+		if delta != 0 {
+			if len(oldLoc) > len(errLoc) {
+				newLoc := gfPolyScale(oldLoc, delta)
+				oldLoc = gfPolyScale(errLoc, gfInverse(delta))
+				errLoc = newLoc
+			}
+			errLoc = gfPolyAdd(errLoc, gfPolyScale(oldLoc, delta))
+		}
+	}
+
+	for len(errLoc) > 0 && errLoc[0] == 0 {
+		errLoc = errLoc[1:]
+	}
+
+	if (len(errLoc)-1)*2 > nsym {
+		return nil, ErrTooManyErrors
+	}
+
+	return errLoc, nil
+}
+
+// rsFindErrors locates the roots of the error locator with a Chien search.
+func rsFindErrors(errLoc []byte, nmess int) ([]int, error) {
+	reversed := make([]byte, len(errLoc))
+	for i := range errLoc {
+		reversed[i] = errLoc[len(errLoc)-1-i]
+	}
+
+	var pos []int
+	for i := 0; i < nmess; i++ {
+		if gfPolyEval(reversed, gfPow(2, i)) == 0 {
+			pos = append(pos, nmess-1-i)
+		}
+	}
+
+	if len(pos) != len(errLoc)-1 {
+		return nil, ErrTooManyErrors
+	}
+
+	return pos, nil
+}
+
+// rsCorrectErrata computes the error magnitudes with the Forney algorithm and applies them.
+func rsCorrectErrata(msg, synd []byte, errPos []int) ([]byte, error) {
+	coefPos := make([]int, len(errPos))
+	for i, p := range errPos {
+		coefPos[i] = len(msg) - 1 - p
+	}
+
+	errLoc := []byte{1}
+	for _, p := range coefPos {
+		errLoc = gfPolyMul(errLoc, gfPolyAdd([]byte{1}, []byte{gfPow(2, p), 0}))
+	}
+
+	reversedSynd := make([]byte, len(synd))
+	for i := range synd {
+		reversedSynd[i] = synd[len(synd)-1-i]
+	}
+
+	divisor := make([]byte, len(errLoc)+1)
+	divisor[0] = 1
+	errEval := gfPolyRemainder(gfPolyMul(reversedSynd, errLoc), divisor)
+
+	X := make([]byte, len(coefPos))
+	for i, p := range coefPos {
+		X[i] = gfPow(2, p-255)
+	}
+
+	out := append([]byte(nil), msg...)
+	for i, Xi := range X {
+		XiInv := gfInverse(Xi)
+
+		var errLocPrime byte = 1
+		for j := range X {
+			if j != i {
+				errLocPrime = gfMul(errLocPrime, 1^gfMul(XiInv, X[j]))
+			}
+		}
+
+		if errLocPrime == 0 {
+			return nil, ErrTooManyErrors
+		}
+
+		y := gfMul(Xi, gfPolyEval(errEval, XiInv))
+		out[errPos[i]] ^= gfDiv(y, errLocPrime)
+	}
+
+	return out, nil
+}
+
+// rsCorrectMsg corrects up to nsym/2 byte errors in a codeword and returns the message part.
+func rsCorrectMsg(msg []byte, nsym int) ([]byte, error) {
+	synd, clean := rsCalcSyndromes(msg, nsym)
+	if clean {
+		return msg[:len(msg)-nsym], nil
+	}
+
+	errLoc, err := rsFindErrorLocator(synd, nsym)
+	if err != nil {
+		return nil, err
+	}
+
+	errPos, err := rsFindErrors(errLoc, len(msg))
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := rsCorrectErrata(msg, synd, errPos)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, clean := rsCalcSyndromes(out, nsym); !clean {
+		return nil, ErrTooManyErrors
+	}
+
+	return out[:len(out)-nsym], nil
+}
+
+// SpreadOrder returns the samples from first up to total in an order stepping through them by a stride near
+// the golden ratio of their count. Any prefix of the order is spread evenly over the image and neighbouring
+// samples hold distant stream positions, so a damaged region of the image hits a small part of the stream.
+func SpreadOrder(first, total int) []int {
+	n := total - first
+	if n <= 0 {
+		return nil
+	}
+
+	stride := max(int(float64(n)*0.6180339887), 1)
+	for gcd(stride, n) != 1 {
+		stride--
+	}
+
+	order := make([]int, n)
+	pos := 0
+	for i := range order {
+		order[i] = first + pos
+		pos = (pos + stride) % n
+	}
+
+	return order
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// ECCEncode protects data with RS(255,223) codewords. The payload length is stored in a shortened
+// codeword of its own, followed by the data codewords interleaved byte by byte so that a burst of
+// damaged bytes is spread over many codewords. Embed the stream in SpreadOrder to also spread
+// a damaged region of the image over the whole stream.
+func ECCEncode(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("data cannot be empty")
+	}
+
+	n := (len(data) + ECCDataSize - 1) / ECCDataSize
+	out := make([]byte, eccHeaderSize+n*ECCBlockSize)
+	copy(out, rsEncodeMsg(intToArr(len(data)), ECCParity))
+
+	body := out[eccHeaderSize:]
+	chunk := make([]byte, ECCDataSize)
+	for j := 0; j < n; j++ {
+		clear(chunk)
+		copy(chunk, data[j*ECCDataSize:min((j+1)*ECCDataSize, len(data))])
+
+		codeword := rsEncodeMsg(chunk, ECCParity)
+		for i, b := range codeword {
+			body[i*n+j] = b
+		}
+	}
+
+	return out, nil
+}
+
+// ECCDecode reverses ECCEncode, correcting up to ECCParity/2 byte errors per codeword.
+// Trailing bytes after the last codeword are ignored.
+func ECCDecode(stream []byte) ([]byte, error) {
+	if len(stream) < eccHeaderSize {
+		return nil, fmt.Errorf("insufficient data: expected at least %d bytes", eccHeaderSize)
+	}
+
+	header, err := rsCorrectMsg(stream[:eccHeaderSize], ECCParity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to correct length header: %w", err)
+	}
+
+	lenData, _ := arrToInt(header)
+	n := (lenData + ECCDataSize - 1) / ECCDataSize
+	if lenData <= 0 || len(stream) < eccHeaderSize+n*ECCBlockSize {
+		return nil, fmt.Errorf("invalid data length: %d", lenData)
+	}
+
+	body := stream[eccHeaderSize:]
+	out := make([]byte, 0, n*ECCDataSize)
+	codeword := make([]byte, ECCBlockSize)
+	for j := 0; j < n; j++ {
+		for i := range codeword {
+			codeword[i] = body[i*n+j]
+		}
+
+		msg, err := rsCorrectMsg(codeword, ECCParity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to correct codeword %d: %w", j, err)
+		}
+
+		out = append(out, msg...)
+	}
+
+	return out[:lenData], nil
+}
@@ -0,0 +1,109 @@
+package pkg
+
+import (
+	"bytes"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestRsCorrectMsg(t *testing.T) {
+	msg := []byte("hello world")
+	codeword := rsEncodeMsg(msg, 10)
+
+	for _, errs := range []int{0, 1, 3, 5} {
+		corrupt := append([]byte(nil), codeword...)
+		for i := 0; i < errs; i++ {
+			corrupt[i*2] ^= 0x5A
+		}
+
+		res, err := rsCorrectMsg(corrupt, 10)
+		if err != nil {
+			t.Fatalf("%d errors: %v", errs, err)
+		}
+
+		if !bytes.Equal(res, msg) {
+			t.Fatalf("%d errors: expected %v, got %v", errs, msg, res)
+		}
+	}
+
+	corrupt := append([]byte(nil), codeword...)
+	for i := 0; i < 6; i++ {
+		corrupt[i] ^= 0xFF
+	}
+
+	if res, err := rsCorrectMsg(corrupt, 10); err == nil && bytes.Equal(res, msg) {
+		t.Fatal("expected failure for more errors than the code can correct")
+	}
+}
+
+func TestECCEncodeDecode(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data := make([]byte, 1000)
+	rng.Read(data)
+
+	stream, err := ECCEncode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// flip random bits all over the stream, about one per 100 bytes
+	for i := 0; i < len(stream)/100; i++ {
+		stream[rng.Intn(len(stream))] ^= 1 << rng.Intn(8)
+	}
+
+	// trailing bytes from the carrier must be ignored
+	stream = append(stream, 1, 2, 3)
+
+	res, err := ECCDecode(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(res, data) {
+		t.Fatal("decoded data does not match original data")
+	}
+}
+
+func TestECCDecodeBurst(t *testing.T) {
+	data := bytes.Repeat([]byte("burst errors are spread by interleaving "), 30)
+
+	stream, err := ECCEncode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a burst of 60 bytes would defeat a single codeword but is spread over all of them
+	for i := 0; i < 60; i++ {
+		stream[eccHeaderSize+100+i] ^= 0xFF
+	}
+
+	res, err := ECCDecode(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(res, data) {
+		t.Fatal("decoded data does not match original data")
+	}
+}
+
+func TestSpreadOrder(t *testing.T) {
+	order := SpreadOrder(16, 30000)
+	if len(order) != 30000-16 {
+		t.Fatalf("expected %d samples, got %d", 30000-16, len(order))
+	}
+
+	seen := make(map[int]bool, len(order))
+	for _, k := range order {
+		if k < 16 || k >= 30000 || seen[k] {
+			t.Fatalf("sample %d is out of range or repeated", k)
+		}
+		seen[k] = true
+	}
+
+	// a short prefix should already reach the far end of the image
+	if last := slices.Max(order[:100]); last < 29000 {
+		t.Fatalf("expected the first 100 samples to be spread over the image, furthest is %d", last)
+	}
+}
@@ -0,0 +1,107 @@
+package pkg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+)
+
+// fragileBlockTag computes the keyed HMAC of a block over the upper 7 bit-planes of its channels.
+// The block position and image geometry are included so blocks cannot be moved or copied between images of other sizes.
+func fragileBlockTag(RGBchannels []RgbChannel, width, height, blockSize, bx, by int, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(intToArr(blockSize))
+	mac.Write(intToArr(width))
+	mac.Write(intToArr(height))
+	mac.Write(intToArr(bx))
+	mac.Write(intToArr(by))
+
+	for y := by; y < min(by+blockSize, height); y++ {
+		for x := bx; x < min(bx+blockSize, width); x++ {
+			c := RGBchannels[y*width+x]
+			mac.Write([]byte{byte(c.R >> 1), byte(c.G >> 1), byte(c.B >> 1)})
+		}
+	}
+
+	return mac.Sum(nil)
+}
+
+// FragileGrid returns the number of block columns and rows covering an image with the given dimensions.
+func FragileGrid(width, height, blockSize int) (cols, rows int) {
+	return (width + blockSize - 1) / blockSize, (height + blockSize - 1) / blockSize
+}
+
+func validateFragile(RGBchannels []RgbChannel, width, height, blockSize int, key []byte) error {
+	if len(key) == 0 {
+		return fmt.Errorf("key cannot be empty")
+	}
+
+	if blockSize < 8 {
+		return fmt.Errorf("block size must be at least 8")
+	}
+
+	if width <= 0 || height <= 0 || len(RGBchannels) != width*height {
+		return fmt.Errorf("rgbchannels do not match image dimensions")
+	}
+
+	return nil
+}
+
+// EmbedFragileWatermark replaces the LSBs of every block with the block's HMAC tag, repeated to fill the block.
+// Any later change to a block, in any bit-plane, breaks its tag.
+func EmbedFragileWatermark(RGBchannels []RgbChannel, width, height int, key []byte, blockSize int) ([]RgbChannel, error) {
+	if err := validateFragile(RGBchannels, width, height, blockSize, key); err != nil {
+		return nil, err
+	}
+
+	for by := 0; by < height; by += blockSize {
+		for bx := 0; bx < width; bx += blockSize {
+			tag := BytesToBinary(fragileBlockTag(RGBchannels, width, height, blockSize, bx, by, key))
+
+			k := 0
+			for y := by; y < min(by+blockSize, height); y++ {
+				for x := bx; x < min(bx+blockSize, width); x++ {
+					c := &RGBchannels[y*width+x]
+					c.R = c.R&^1 | uint32(tag[k%len(tag)])
+					c.G = c.G&^1 | uint32(tag[(k+1)%len(tag)])
+					c.B = c.B&^1 | uint32(tag[(k+2)%len(tag)])
+					k += 3
+				}
+			}
+		}
+	}
+
+	return RGBchannels, nil
+}
+
+// VerifyFragileWatermark recomputes the tag of every block and compares it to the LSBs.
+// It returns one entry per block in row major order, true for blocks that were tampered with.
+func VerifyFragileWatermark(RGBchannels []RgbChannel, width, height int, key []byte, blockSize int) ([]bool, error) {
+	if err := validateFragile(RGBchannels, width, height, blockSize, key); err != nil {
+		return nil, err
+	}
+
+	cols, rows := FragileGrid(width, height, blockSize)
+	tampered := make([]bool, cols*rows)
+
+	for by := 0; by < height; by += blockSize {
+		for bx := 0; bx < width; bx += blockSize {
+			tag := BytesToBinary(fragileBlockTag(RGBchannels, width, height, blockSize, bx, by, key))
+			idx := (by/blockSize)*cols + bx/blockSize
+
+			k := 0
+			for y := by; y < min(by+blockSize, height) && !tampered[idx]; y++ {
+				for x := bx; x < min(bx+blockSize, width); x++ {
+					c := RGBchannels[y*width+x]
+					if uint8(c.R&1) != tag[k%len(tag)] || uint8(c.G&1) != tag[(k+1)%len(tag)] || uint8(c.B&1) != tag[(k+2)%len(tag)] {
+						tampered[idx] = true
+						break
+					}
+					k += 3
+				}
+			}
+		}
+	}
+
+	return tampered, nil
+}
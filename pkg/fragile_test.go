@@ -0,0 +1,80 @@
+package pkg
+
+import "testing"
+
+func TestFragileWatermark(t *testing.T) {
+	width, height := 40, 24
+	key := []byte("secret key")
+
+	channels, err := EmbedFragileWatermark(createTexturedChannels(width, height), width, height, key, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered, err := VerifyFragileWatermark(channels, width, height, key, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, bad := range tampered {
+		if bad {
+			t.Fatalf("block %d reported as tampered on an untouched image", i)
+		}
+	}
+
+	// edit one pixel in block (2, 1) and the MSB of a pixel in block (4, 2)
+	channels[10*width+20].R ^= 1
+	channels[20*width+35].G ^= 0x80
+
+	tampered, err = VerifyFragileWatermark(channels, width, height, key, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cols, _ := FragileGrid(width, height, 8)
+	for i, bad := range tampered {
+		expected := i == 1*cols+2 || i == 2*cols+4
+		if bad != expected {
+			t.Fatalf("block %d: expected tampered=%v, got %v", i, expected, bad)
+		}
+	}
+
+	tampered, err = VerifyFragileWatermark(channels, width, height, []byte("wrong key"), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, bad := range tampered {
+		if !bad {
+			t.Fatalf("block %d verified with the wrong key", i)
+		}
+	}
+}
+
+func TestFragileWatermarkPartialBlocks(t *testing.T) {
+	width, height := 45, 33
+	key := []byte("secret key")
+
+	channels, err := EmbedFragileWatermark(createTexturedChannels(width, height), width, height, key, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	channels[len(channels)-1].B ^= 1
+
+	tampered, err := VerifyFragileWatermark(channels, width, height, key, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []bool{false, false, false, true}
+	for i := range expected {
+		if tampered[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, tampered)
+		}
+	}
+
+	if _, err := EmbedFragileWatermark(channels, width, height, key, 4); err == nil {
+		t.Fatal("expected error for block size below 8")
+	}
+}
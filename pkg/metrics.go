@@ -0,0 +1,176 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+)
+
+// ssimWindow is the edge length of the windows SSIM is averaged over, windows overlap by half.
+const ssimWindow = 8
+
+// SSIM constants for 8 bit samples
+const (
+	ssimC1 = (0.01 * 255) * (0.01 * 255)
+	ssimC2 = (0.03 * 255) * (0.03 * 255)
+)
+
+// segmentalSNRFrame is the number of samples per frame of the segmental SNR,
+// per frame values are clamped to the usual [-10, 35] dB range.
+const (
+	segmentalSNRFrame = 256
+	segmentalSNRMin   = -10.0
+	segmentalSNRMax   = 35.0
+)
+
+func validateComparison(a, b []RgbChannel, width int) error {
+	if len(a) == 0 || len(a) != len(b) {
+		return fmt.Errorf("channels are empty or have different lengths")
+	}
+
+	if width <= 0 || len(a)%width != 0 {
+		return fmt.Errorf("invalid image width: %d", width)
+	}
+
+	return nil
+}
+
+// ModifiedChannels counts the channel samples that differ between a and b.
+func ModifiedChannels(a, b []RgbChannel) int {
+	n := 0
+	for i := range a {
+		if a[i].R != b[i].R {
+			n++
+		}
+		if a[i].G != b[i].G {
+			n++
+		}
+		if a[i].B != b[i].B {
+			n++
+		}
+	}
+
+	return n
+}
+
+// MSE returns the mean squared error over all channel samples of a and b.
+func MSE(a, b []RgbChannel) (float64, error) {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0, fmt.Errorf("channels are empty or have different lengths")
+	}
+
+	sum := 0.0
+	for i := range a {
+		dr := float64(a[i].R) - float64(b[i].R)
+		dg := float64(a[i].G) - float64(b[i].G)
+		db := float64(a[i].B) - float64(b[i].B)
+		sum += dr*dr + dg*dg + db*db
+	}
+
+	return sum / float64(len(a)*3), nil
+}
+
+// PSNR converts a mean squared error of 8 bit samples into a peak signal to noise ratio in dB, +Inf for identical images.
+func PSNR(mse float64) float64 {
+	if mse == 0 {
+		return math.Inf(1)
+	}
+
+	return 10 * math.Log10(255*255/mse)
+}
+
+// SSIM returns the structural similarity of a and b, averaged over overlapping windows and the three channels.
+// 1 means the images are identical.
+func SSIM(a, b []RgbChannel, width int) (float64, error) {
+	if err := validateComparison(a, b, width); err != nil {
+		return 0, err
+	}
+
+	height := len(a) / width
+	win := min(ssimWindow, width, height)
+	step := max(win/2, 1)
+
+	total := 0.0
+	windows := 0
+	for k := 0; k < 3; k++ {
+		for y := 0; y+win <= height; y += step {
+			for x := 0; x+win <= width; x += step {
+				total += ssimAt(a, b, width, x, y, win, k)
+				windows++
+			}
+		}
+	}
+
+	return total / float64(windows), nil
+}
+
+func ssimAt(a, b []RgbChannel, width, x0, y0, win, k int) float64 {
+	var sa, sb, saa, sbb, sab float64
+	for y := y0; y < y0+win; y++ {
+		for x := x0; x < x0+win; x++ {
+			i := (y*width + x) * 3
+			va := float64(getSample(a, i+k))
+			vb := float64(getSample(b, i+k))
+			sa += va
+			sb += vb
+			saa += va * va
+			sbb += vb * vb
+			sab += va * vb
+		}
+	}
+
+	n := float64(win * win)
+	ma, mb := sa/n, sb/n
+	varA := saa/n - ma*ma
+	varB := sbb/n - mb*mb
+	cov := sab/n - ma*mb
+
+	return ((2*ma*mb + ssimC1) * (2*cov + ssimC2)) / ((ma*ma + mb*mb + ssimC1) * (varA + varB + ssimC2))
+}
+
+// SNR returns the signal to noise ratio in dB of the modified samples against the original ones, +Inf if they are identical.
+func SNR(original, modified []int) (float64, error) {
+	if len(original) == 0 || len(original) != len(modified) {
+		return 0, fmt.Errorf("samples are empty or have different lengths")
+	}
+
+	return snr(original, modified), nil
+}
+
+func snr(original, modified []int) float64 {
+	var signal, noise float64
+	for i := range original {
+		s := float64(original[i])
+		d := s - float64(modified[i])
+		signal += s * s
+		noise += d * d
+	}
+
+	if noise == 0 {
+		return math.Inf(1)
+	}
+
+	if signal == 0 {
+		return math.Inf(-1)
+	}
+
+	return 10 * math.Log10(signal/noise)
+}
+
+// SegmentalSNR returns the average SNR of consecutive frames, which follows perceived quality
+// more closely than SNR because quiet passages are not drowned out by loud ones.
+func SegmentalSNR(original, modified []int) (float64, error) {
+	if len(original) == 0 || len(original) != len(modified) {
+		return 0, fmt.Errorf("samples are empty or have different lengths")
+	}
+
+	total := 0.0
+	frames := 0
+	for start := 0; start < len(original); start += segmentalSNRFrame {
+		end := min(start+segmentalSNRFrame, len(original))
+		frame := snr(original[start:end], modified[start:end])
+		total += min(max(frame, segmentalSNRMin), segmentalSNRMax)
+		frames++
+	}
+
+	return total / float64(frames), nil
+}
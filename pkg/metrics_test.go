@@ -0,0 +1,111 @@
+package pkg
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMSEAndPSNR(t *testing.T) {
+	a := []RgbChannel{{R: 10, G: 20, B: 30}, {R: 40, G: 50, B: 60}}
+	b := []RgbChannel{{R: 12, G: 20, B: 30}, {R: 40, G: 50, B: 59}}
+
+	mse, err := MSE(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := 5.0 / 6; math.Abs(mse-want) > 1e-12 {
+		t.Fatalf("expected MSE %v, got %v", want, mse)
+	}
+
+	if want := 10 * math.Log10(255*255/mse); math.Abs(PSNR(mse)-want) > 1e-9 {
+		t.Fatalf("expected PSNR %v, got %v", want, PSNR(mse))
+	}
+
+	if !math.IsInf(PSNR(0), 1) {
+		t.Fatal("expected infinite PSNR for identical images")
+	}
+
+	if n := ModifiedChannels(a, b); n != 2 {
+		t.Fatalf("expected 2 modified channels, got %d", n)
+	}
+
+	if _, err := MSE(a, b[:1]); err == nil {
+		t.Fatal("expected error for different lengths")
+	}
+}
+
+func TestSSIM(t *testing.T) {
+	w, h := 32, 32
+	a := createTexturedChannels(w, h)
+
+	same, err := SSIM(a, append([]RgbChannel(nil), a...), w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(same-1) > 1e-9 {
+		t.Fatalf("expected SSIM 1 for identical images, got %v", same)
+	}
+
+	lsb := append([]RgbChannel(nil), a...)
+	flat := make([]RgbChannel, len(a))
+	for i := range lsb {
+		lsb[i].R ^= 1
+		flat[i] = RgbChannel{R: 128, G: 128, B: 128}
+	}
+
+	slight, err := SSIM(a, lsb, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	destroyed, err := SSIM(a, flat, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !(slight < 1 && slight > destroyed) {
+		t.Fatalf("expected 1 > %v > %v", slight, destroyed)
+	}
+
+	if _, err := SSIM(a, lsb, 33); err == nil {
+		t.Fatal("expected error for invalid width")
+	}
+}
+
+func TestSNR(t *testing.T) {
+	original := make([]int, 1000)
+	modified := make([]int, 1000)
+	for i := range original {
+		original[i] = int(1000 * math.Sin(float64(i)/10))
+		modified[i] = original[i] + 1
+	}
+
+	snr, err := SNR(original, modified)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signal := 0.0
+	for _, s := range original {
+		signal += float64(s * s)
+	}
+
+	if want := 10 * math.Log10(signal/1000); math.Abs(snr-want) > 1e-9 {
+		t.Fatalf("expected SNR %v, got %v", want, snr)
+	}
+
+	segmental, err := SegmentalSNR(original, original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if segmental != segmentalSNRMax {
+		t.Fatalf("expected segmental SNR of identical signals to be clamped to %v, got %v", segmentalSNRMax, segmental)
+	}
+
+	if _, err := SegmentalSNR(original, modified[:10]); err == nil {
+		t.Fatal("expected error for different lengths")
+	}
+}
@@ -0,0 +1,82 @@
+package pkg
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+)
+
+// NewNoiseReader returns a source of fill bits. A nil key yields the CSPRNG,
+// any other key yields an AES-CTR keystream derived from it. The IV is derived from the bits of the cover
+// above depth, which embedding leaves untouched, so two covers filled with the same key get unrelated noise.
+func NewNoiseReader(key []byte, RGBchannels []RgbChannel, depth uint8) (io.Reader, error) {
+	if key == nil {
+		return rand.Reader, nil
+	}
+
+	k := sha256.Sum256(key)
+	block, err := aes.NewCipher(k[:])
+	if err != nil {
+		return nil, err
+	}
+
+	iv := coverIV(RGBchannels, depth)
+	return cipher.StreamReader{S: cipher.NewCTR(block, iv), R: zeroReader{}}, nil
+}
+
+// coverIV hashes the bits of every sample above depth into an AES block sized IV.
+func coverIV(RGBchannels []RgbChannel, depth uint8) []byte {
+	h := sha256.New()
+	h.Write([]byte{depth})
+
+	buf := make([]byte, 0, 3*1024)
+	for i, c := range RGBchannels {
+		buf = append(buf, byte(c.R>>(depth+1)), byte(c.G>>(depth+1)), byte(c.B>>(depth+1)))
+		if len(buf) == cap(buf) || i == len(RGBchannels)-1 {
+			h.Write(buf)
+			buf = buf[:0]
+		}
+	}
+
+	return h.Sum(nil)[:aes.BlockSize]
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// EmbedIntoRGBchannelsWithNoise embeds data like EmbedIntoRGBchannelsWithDepth and then fills
+// the remaining capacity at the same depth with bits read from noise, so the end of the payload
+// is not visible in the image. Extraction is unaffected because the length prefix is kept.
+func EmbedIntoRGBchannelsWithNoise(RGBchannels []RgbChannel, data []byte, depth uint8, noise io.Reader) ([]RgbChannel, error) {
+	return EmbedIntoRGBchannelsWithNoiseMonitored(RGBchannels, data, depth, noise, nil)
+}
+
+// EmbedIntoRGBchannelsWithNoiseMonitored works like EmbedIntoRGBchannelsWithNoise, reporting to m
+// and stopping with the context's error once it is cancelled.
+func EmbedIntoRGBchannelsWithNoiseMonitored(RGBchannels []RgbChannel, data []byte, depth uint8, noise io.Reader, m *Monitor) ([]RgbChannel, error) {
+	if depth > 7 {
+		return nil, fmt.Errorf("bit depth exeeds 7")
+	}
+
+	capacity := (len(RGBchannels)*3*(int(depth)+1) + 7) / 8
+	if len(data)+4 > capacity {
+		return nil, fmt.Errorf("data is too big")
+	}
+
+	stream := make([]byte, capacity)
+	copy(stream, intToArr(len(data)))
+	copy(stream[4:], data)
+
+	if _, err := io.ReadFull(noise, stream[4+len(data):]); err != nil {
+		return nil, fmt.Errorf("failed to read noise: %w", err)
+	}
+
+	return EmbedRawIntoRGBchannelsWithDepthMonitored(RGBchannels, stream, depth, m)
+}
@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestNewNoiseReaderKeyed(t *testing.T) {
+	cover := createTexturedChannels(8, 8)
+
+	read := func(key []byte, channels []RgbChannel, depth uint8) []byte {
+		t.Helper()
+
+		noise, err := NewNoiseReader(key, channels, depth)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		buf := make([]byte, 64)
+		io.ReadFull(noise, buf)
+		return buf
+	}
+
+	a := read([]byte("key"), cover, 1)
+	if !bytes.Equal(a, read([]byte("key"), cover, 1)) {
+		t.Fatal("keyed noise is not reproducible")
+	}
+
+	if bytes.Equal(a, make([]byte, 64)) {
+		t.Fatal("keyed noise is all zeros")
+	}
+
+	// changing only the bits at or below the depth must not change the noise
+	embedded, err := EmbedIntoRGBchannelsWithDepth(slices.Clone(cover), []byte("payload"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(a, read([]byte("key"), embedded, 1)) {
+		t.Fatal("keyed noise depends on the embedded bits")
+	}
+
+	if bytes.Equal(a, read([]byte("key"), createTexturedChannels(8, 9), 1)) {
+		t.Fatal("different covers got the same noise")
+	}
+}
+
+func TestEmbedIntoRGBchannelsWithNoise(t *testing.T) {
+	rgbdata := make([]RgbChannel, 64)
+	for i := range rgbdata {
+		rgbdata[i] = RgbChannel{R: 255, G: 255, B: 255}
+	}
+
+	noise, err := NewNoiseReader([]byte("key"), rgbdata, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("hey")
+	ec, err := EmbedIntoRGBchannelsWithNoise(rgbdata, data, 0, noise)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the last pixel lies far beyond the payload and should have been touched by the noise
+	changed := false
+	for _, c := range ec[20:] {
+		if c.R != 255 || c.G != 255 || c.B != 255 {
+			changed = true
+			break
+		}
+	}
+
+	if !changed {
+		t.Fatal("remaining capacity was not filled with noise")
+	}
+
+	edata, err := ExtractDataFromRGBchannelsWithDepth(ec, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := GetlenOfData(edata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(edata[4:4+n], data) {
+		t.Fatalf("expected %v, got %v", data, edata[4:4+n])
+	}
+
+	if _, err := EmbedIntoRGBchannelsWithNoise(rgbdata[:2], data, 0, noise); err == nil {
+		t.Fatal("expected error for data larger than capacity")
+	}
+}
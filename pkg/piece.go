@@ -0,0 +1,114 @@
+package pkg
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// PieceHeaderSize is the size of the header in front of every piece of a payload spread over several carriers.
+const PieceHeaderSize = 2 + 1 + 8 + 2 + 2 + 2 + 4 + 4
+
+var pieceMagic = [2]byte{'S', 'G'}
+
+// Piece kinds
+const (
+	// PieceSplit is a slice of the payload, all pieces of the set are needed.
+	PieceSplit uint8 = 1
+	// PieceShare is a Shamir share of the payload, any Threshold pieces of the set are needed.
+	PieceShare uint8 = 2
+)
+
+var ErrNotAPiece = errors.New("data is not a piece of a multi-carrier payload")
+
+// PieceHeader identifies a piece of a payload and the set it belongs to.
+type PieceHeader struct {
+	Kind  uint8
+	SetID [8]byte
+	// Seq is the position of the piece in the set, starting at 0.
+	Seq uint16
+	// Total is the number of pieces in the set.
+	Total uint16
+	// Threshold is the number of pieces needed to recover the payload, equal to Total for split payloads.
+	Threshold uint16
+	// Length is the size of the complete payload. Checksum covers the complete payload for split pieces,
+	// so a reassembled payload can be verified, and the piece body for shares.
+	Length   uint32
+	Checksum uint32
+}
+
+// Checksum returns the checksum stored in PieceHeader.Checksum for a payload.
+func Checksum(data []byte) uint32 {
+	return crc32.ChecksumIEEE(data)
+}
+
+// MarshalPiece prepends the encoded header to body.
+func MarshalPiece(h PieceHeader, body []byte) []byte {
+	out := make([]byte, 0, PieceHeaderSize+len(body))
+	out = append(out, pieceMagic[:]...)
+	out = append(out, h.Kind)
+	out = append(out, h.SetID[:]...)
+	out = binary.BigEndian.AppendUint16(out, h.Seq)
+	out = binary.BigEndian.AppendUint16(out, h.Total)
+	out = binary.BigEndian.AppendUint16(out, h.Threshold)
+	out = binary.BigEndian.AppendUint32(out, h.Length)
+	out = binary.BigEndian.AppendUint32(out, h.Checksum)
+	return append(out, body...)
+}
+
+// ParsePiece splits a piece produced by MarshalPiece into its header and body.
+func ParsePiece(piece []byte) (PieceHeader, []byte, error) {
+	var h PieceHeader
+	if len(piece) < PieceHeaderSize || piece[0] != pieceMagic[0] || piece[1] != pieceMagic[1] {
+		return h, nil, ErrNotAPiece
+	}
+
+	h.Kind = piece[2]
+	copy(h.SetID[:], piece[3:11])
+	h.Seq = binary.BigEndian.Uint16(piece[11:13])
+	h.Total = binary.BigEndian.Uint16(piece[13:15])
+	h.Threshold = binary.BigEndian.Uint16(piece[15:17])
+	h.Length = binary.BigEndian.Uint32(piece[17:21])
+	h.Checksum = binary.BigEndian.Uint32(piece[21:25])
+
+	if h.Total == 0 || h.Seq >= h.Total || h.Threshold == 0 || h.Threshold > h.Total {
+		return h, nil, ErrNotAPiece
+	}
+
+	return h, piece[PieceHeaderSize:], nil
+}
+
+// SplitSizes divides length bytes over carriers with the given capacities, proportional to each capacity.
+// It returns nil if the capacities are too small.
+func SplitSizes(length int, capacities []int) []int {
+	total := 0
+	for _, c := range capacities {
+		if c < 0 {
+			return nil
+		}
+		total += c
+	}
+
+	if total < length || len(capacities) == 0 {
+		return nil
+	}
+
+	sizes := make([]int, len(capacities))
+	assigned := 0
+	for i, c := range capacities {
+		if total > 0 {
+			sizes[i] = int(int64(length) * int64(c) / int64(total))
+		}
+		assigned += sizes[i]
+	}
+
+	// hand out the bytes lost to rounding to carriers with room left, in order
+	for i := 0; assigned < length; i = (i + 1) % len(sizes) {
+		if sizes[i] < capacities[i] {
+			sizes[i]++
+			assigned++
+		}
+	}
+
+	return sizes
+}
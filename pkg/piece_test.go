@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalParsePiece(t *testing.T) {
+	h := PieceHeader{
+		Kind:      PieceSplit,
+		SetID:     [8]byte{1, 2, 3, 4, 5, 6, 7, 8},
+		Seq:       2,
+		Total:     3,
+		Threshold: 3,
+		Length:    1234,
+		Checksum:  Checksum([]byte("payload")),
+	}
+
+	piece := MarshalPiece(h, []byte("body"))
+	if len(piece) != PieceHeaderSize+4 {
+		t.Fatalf("expected %d bytes, got %d", PieceHeaderSize+4, len(piece))
+	}
+
+	parsed, body, err := ParsePiece(piece)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed != h || !bytes.Equal(body, []byte("body")) {
+		t.Fatalf("expected %+v %q, got %+v %q", h, "body", parsed, body)
+	}
+
+	piece[0] ^= 0xFF
+	if _, _, err := ParsePiece(piece); err != ErrNotAPiece {
+		t.Fatalf("expected %v for bad magic, got %v", ErrNotAPiece, err)
+	}
+
+	h.Seq = 3
+	if _, _, err := ParsePiece(MarshalPiece(h, nil)); err != ErrNotAPiece {
+		t.Fatalf("expected %v for sequence out of range, got %v", ErrNotAPiece, err)
+	}
+}
+
+func TestSplitSizes(t *testing.T) {
+	tests := []struct {
+		name       string
+		length     int
+		capacities []int
+		expected   []int
+	}{
+		{name: "Proportional", length: 100, capacities: []int{100, 300}, expected: []int{25, 75}},
+		{name: "Rounding remainder", length: 10, capacities: []int{10, 10, 10}, expected: []int{4, 3, 3}},
+		{name: "Exact fit", length: 30, capacities: []int{10, 20}, expected: []int{10, 20}},
+		{name: "Empty carrier", length: 5, capacities: []int{0, 10}, expected: []int{0, 5}},
+		{name: "Too large", length: 31, capacities: []int{10, 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sizes := SplitSizes(tt.length, tt.capacities)
+			if !reflect.DeepEqual(sizes, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, sizes)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package pkg
+
+import "context"
+
+// ProgressInterval is the number of bits the embedding and extraction loops process between two calls to a Monitor.
+const ProgressInterval = 1 << 16
+
+// Monitor lets the embedding and extraction loops report their progress and stop once a context is cancelled.
+// A nil *Monitor does nothing.
+type Monitor struct {
+	ctx    context.Context
+	report func(done, total int64)
+}
+
+// NewMonitor returns a Monitor checking ctx and passing the progress to report, either may be nil.
+func NewMonitor(ctx context.Context, report func(done, total int64)) *Monitor {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return &Monitor{ctx: ctx, report: report}
+}
+
+// Step reports that done of total bits are processed and returns the context's error once it is cancelled.
+func (m *Monitor) Step(done, total int64) error {
+	if m == nil {
+		return nil
+	}
+
+	if err := m.ctx.Err(); err != nil {
+		return err
+	}
+
+	if m.report != nil {
+		m.report(done, total)
+	}
+
+	return nil
+}
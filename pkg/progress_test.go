@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMonitor_Progress(t *testing.T) {
+	channels := createTexturedChannels(200, 200)
+	data := bytes.Repeat([]byte{0xA5}, 20000)
+
+	var calls int
+	var last, total int64
+	m := NewMonitor(context.Background(), func(done, t int64) {
+		calls++
+		last, total = done, t
+	})
+
+	embedded, err := EmbedIntoRGBchannelsWithDepthMonitored(channels, data, 1, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls < 2 {
+		t.Fatalf("expected several progress calls, got %d", calls)
+	}
+
+	if want := int64(len(data)+4) * 8; last != want || total != want {
+		t.Fatalf("expected final progress %d/%d, got %d/%d", want, want, last, total)
+	}
+
+	got, err := ExtractDataFromRGBchannelsWithDepthMonitored(embedded, 1, NewMonitor(nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got[4:len(data)+4], data) {
+		t.Fatal("extracted data does not match original")
+	}
+}
+
+func TestMonitor_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// cancel halfway through the embedding
+	m := NewMonitor(ctx, func(done, total int64) {
+		if done*2 >= total {
+			cancel()
+		}
+	})
+
+	data := bytes.Repeat([]byte{0x5A}, 20000)
+	if _, err := EmbedIntoRGBchannelsWithDepthMonitored(createTexturedChannels(200, 200), data, 1, m); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error: %v, got: %v", context.Canceled, err)
+	}
+
+	if _, err := ExtractDataFromRGBchannelsWithDepthMonitored(createTexturedChannels(200, 200), 1, m); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error: %v, got: %v", context.Canceled, err)
+	}
+
+	var nilMonitor *Monitor
+	if err := nilMonitor.Step(1, 2); err != nil {
+		t.Fatalf("expected nil monitor to do nothing, got: %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+)
+
+// pvdRanges is the quantization range table of Wu and Tsai. A pixel pair whose difference falls
+// into a wider range, i.e. a textured area, carries more bits.
+var pvdRanges = [][2]int{{0, 7}, {8, 15}, {16, 31}, {32, 63}, {64, 127}, {128, 255}}
+
+// pvdRange returns the lower bound of the range containing the absolute difference d and the number of bits it carries.
+func pvdRange(d int) (lower, upper, bits int) {
+	for _, r := range pvdRanges {
+		if d <= r[1] {
+			return r[0], r[1], int(math.Log2(float64(r[1] - r[0] + 1)))
+		}
+	}
+
+	return 0, 0, 0
+}
+
+// pvdPair moves the pair (a, b) so that b - a equals newDiff, splitting the change between both pixels.
+func pvdPair(a, b, newDiff int) (int, int) {
+	d := b - a
+	m := float64(newDiff - d)
+	if d%2 != 0 {
+		return a - int(math.Ceil(m/2)), b + int(math.Floor(m/2))
+	}
+
+	return a - int(math.Floor(m/2)), b + int(math.Ceil(m/2))
+}
+
+// pvdUsable reports whether a pair can carry bits, i.e. whether moving it to the upper bound of its range
+// keeps both pixels inside 0-255. The decoder repeats the test on the stego pair, which lies in the same range.
+func pvdUsable(a, b int) (lower, bits int, ok bool) {
+	d := b - a
+	lower, upper, bits := pvdRange(abs(d))
+	if d < 0 {
+		upper = -upper
+	}
+
+	na, nb := pvdPair(a, b, upper)
+	if na < 0 || na > 255 || nb < 0 || nb > 255 {
+		return 0, 0, false
+	}
+
+	return lower, bits, true
+}
+
+// pvdPairs calls fn for every pair of horizontally adjacent pixels in each channel, in embedding order.
+func pvdPairs(RGBchannels []RgbChannel, fn func(a, b *uint32) bool) {
+	for i := 0; i+1 < len(RGBchannels); i += 2 {
+		p, q := &RGBchannels[i], &RGBchannels[i+1]
+		if !fn(&p.R, &q.R) || !fn(&p.G, &q.G) || !fn(&p.B, &q.B) {
+			return
+		}
+	}
+}
+
+// PVDCapacity returns how many bytes can be embedded into the channels with EmbedPVD.
+// The capacity depends on the content, textured images hold more than smooth ones.
+func PVDCapacity(RGBchannels []RgbChannel) int {
+	total := 0
+	pvdPairs(RGBchannels, func(a, b *uint32) bool {
+		if _, bits, ok := pvdUsable(int(*a), int(*b)); ok {
+			total += bits
+		}
+		return true
+	})
+
+	if total < 32 {
+		return 0
+	}
+
+	return (total - 32) / 8
+}
+
+// EmbedPVD embeds data with pixel value differencing: every usable pixel pair carries between 3 and 7 bits
+// depending on the difference between its pixels, so smooth regions are barely touched.
+func EmbedPVD(RGBchannels []RgbChannel, data []byte) ([]RgbChannel, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("data cannot be empty")
+	}
+
+	if len(data) > PVDCapacity(RGBchannels) {
+		return nil, fmt.Errorf("data is too big")
+	}
+
+	stream := append(Int32ToBinary(int32(len(data))), BytesToBinary(data)...)
+	idx := 0
+
+	pvdPairs(RGBchannels, func(a, b *uint32) bool {
+		if idx >= len(stream) {
+			return false
+		}
+
+		lower, bits, ok := pvdUsable(int(*a), int(*b))
+		if !ok {
+			return true
+		}
+
+		value := 0
+		for j := 0; j < bits; j++ {
+			value <<= 1
+			if idx < len(stream) {
+				value |= int(stream[idx])
+				idx++
+			}
+		}
+
+		newDiff := lower + value
+		if int(*b) < int(*a) {
+			newDiff = -newDiff
+		}
+
+		na, nb := pvdPair(int(*a), int(*b), newDiff)
+		*a, *b = uint32(na), uint32(nb)
+		return true
+	})
+
+	return RGBchannels, nil
+}
+
+// ExtractPVD extracts data embedded with EmbedPVD.
+func ExtractPVD(RGBchannels []RgbChannel) ([]byte, error) {
+	var stream []uint8
+	need := 32
+
+	pvdPairs(RGBchannels, func(a, b *uint32) bool {
+		lower, bits, ok := pvdUsable(int(*a), int(*b))
+		if !ok {
+			return true
+		}
+
+		value := abs(int(*b)-int(*a)) - lower
+		for j := bits - 1; j >= 0; j-- {
+			stream = append(stream, uint8(value>>j)&1)
+		}
+
+		if need == 32 && len(stream) >= 32 {
+			need = 32 + bitsToInt(stream[:32])*8
+		}
+
+		return len(stream) < need
+	})
+
+	if len(stream) < 32 {
+		return nil, fmt.Errorf("insufficient data: expected at least 32 bits")
+	}
+
+	lenData := bitsToInt(stream[:32])
+	if lenData <= 0 || len(stream) < 32+lenData*8 {
+		return nil, fmt.Errorf("invalid data length %d", lenData)
+	}
+
+	data := make([]byte, lenData)
+	for i := range data {
+		data[i] = byte(bitsToInt(stream[32+i*8 : 32+(i+1)*8]))
+	}
+
+	return data, nil
+}
@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestPVDRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+
+	noisy := make([]RgbChannel, 64*64)
+	for i := range noisy {
+		noisy[i] = RgbChannel{R: uint32(rng.Intn(256)), G: uint32(rng.Intn(256)), B: uint32(rng.Intn(256))}
+	}
+
+	tests := []struct {
+		name     string
+		channels []RgbChannel
+	}{
+		{name: "Textured image", channels: createTexturedChannels(64, 64)},
+		{name: "Random image", channels: noisy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capacity := PVDCapacity(tt.channels)
+			data := make([]byte, capacity)
+			rng.Read(data)
+
+			stego, err := EmbedPVD(tt.channels, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, c := range stego {
+				if c.R > 255 || c.G > 255 || c.B > 255 {
+					t.Fatalf("channel value out of range: %v", c)
+				}
+			}
+
+			extracted, err := ExtractPVD(stego)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(extracted, data) {
+				t.Fatal("extracted data does not match")
+			}
+		})
+	}
+}
+
+func TestPVDCapacityDependsOnContent(t *testing.T) {
+	smooth := make([]RgbChannel, 64*64)
+	for i := range smooth {
+		smooth[i] = RgbChannel{R: 100, G: 100, B: 100}
+	}
+
+	if PVDCapacity(createTexturedChannels(64, 64)) <= PVDCapacity(smooth) {
+		t.Fatal("expected textured image to have a larger capacity than a smooth one")
+	}
+
+	if _, err := EmbedPVD(smooth, make([]byte, PVDCapacity(smooth)+1)); err == nil {
+		t.Fatal("expected error for data exceeding the capacity")
+	}
+}
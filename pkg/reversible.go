@@ -0,0 +1,255 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// reversibleHeaderSamples is the number of channel samples whose LSBs hold the peak (8 bits),
+// the zero point (8 bits) and the size of the location map (32 bits).
+const reversibleHeaderSamples = 48
+
+var ErrNoZeroPoint = errors.New("no usable zero point found in the histogram")
+
+func getSample(RGBchannels []RgbChannel, k int) uint32 {
+	c := RGBchannels[k/3]
+	switch k % 3 {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	}
+	return c.B
+}
+
+func setSample(RGBchannels []RgbChannel, k int, v uint32) {
+	c := &RGBchannels[k/3]
+	switch k % 3 {
+	case 0:
+		c.R = v
+	case 1:
+		c.G = v
+	default:
+		c.B = v
+	}
+}
+
+func bitsToInt(bits []uint8) int {
+	n := 0
+	for _, b := range bits {
+		n = n<<1 | int(b)
+	}
+	return n
+}
+
+// reversibleZeroPoint picks the bin the histogram is shifted towards. It prefers the emptiest bin so the
+// location map stays small. A bin directly next to the peak is only usable when it is empty, otherwise
+// its pixels could not be told apart from embedded bits.
+func reversibleZeroPoint(hist []int, peak int) (int, bool) {
+	best := -1
+	for z := 0; z < 256; z++ {
+		if z == peak || ((z == peak+1 || z == peak-1) && hist[z] > 0) {
+			continue
+		}
+
+		if best == -1 || hist[z] < hist[best] || (hist[z] == hist[best] && abs(z-peak) < abs(best-peak)) {
+			best = z
+		}
+	}
+
+	return best, best != -1
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// ReversibleCapacity returns how many bytes can be embedded reversibly into the channels.
+func ReversibleCapacity(RGBchannels []RgbChannel) int {
+	samples := len(RGBchannels) * 3
+	if samples <= reversibleHeaderSamples {
+		return 0
+	}
+
+	hist := make([]int, 256)
+	for k := reversibleHeaderSamples; k < samples; k++ {
+		hist[getSample(RGBchannels, k)&0xFF]++
+	}
+
+	peak := 0
+	for v := range hist {
+		if hist[v] > hist[peak] {
+			peak = v
+		}
+	}
+
+	zero, ok := reversibleZeroPoint(hist, peak)
+	if !ok {
+		return 0
+	}
+
+	bits := hist[peak] - reversibleHeaderSamples - 32*hist[zero] - 32
+	if bits < 0 {
+		return 0
+	}
+
+	return bits / 8
+}
+
+// EmbedReversible hides data with histogram shifting: samples between the histogram peak and the zero point
+// are shifted by one towards the zero point, freeing the bin next to the peak, and every sample equal to the
+// peak carries one bit. Samples originally equal to the zero point are recorded in a location map, and the
+// original LSBs of the header samples are stored with the payload, so the cover can be restored bit for bit.
+// The channels must hold 8-bit values.
+func EmbedReversible(RGBchannels []RgbChannel, data []byte) ([]RgbChannel, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("data cannot be empty")
+	}
+
+	samples := len(RGBchannels) * 3
+	if samples <= reversibleHeaderSamples {
+		return nil, fmt.Errorf("image is too small")
+	}
+
+	hist := make([]int, 256)
+	for k := reversibleHeaderSamples; k < samples; k++ {
+		v := getSample(RGBchannels, k)
+		if v > 255 {
+			return nil, fmt.Errorf("channel value %d exceeds 8 bits", v)
+		}
+		hist[v]++
+	}
+
+	peak := 0
+	for v := range hist {
+		if hist[v] > hist[peak] {
+			peak = v
+		}
+	}
+
+	zero, ok := reversibleZeroPoint(hist, peak)
+	if !ok {
+		return nil, ErrNoZeroPoint
+	}
+
+	stream := make([]uint8, 0, reversibleHeaderSamples+32*hist[zero]+32+len(data)*8)
+	for k := 0; k < reversibleHeaderSamples; k++ {
+		stream = append(stream, uint8(getSample(RGBchannels, k)&1))
+	}
+
+	for k := reversibleHeaderSamples; k < samples; k++ {
+		if getSample(RGBchannels, k) == uint32(zero) {
+			stream = append(stream, Int32ToBinary(int32(k))...)
+		}
+	}
+
+	stream = append(stream, Int32ToBinary(int32(len(data)))...)
+	stream = append(stream, BytesToBinary(data)...)
+
+	if len(stream) > hist[peak] {
+		return nil, fmt.Errorf("data is too big: %d bits needed, %d available", len(stream), hist[peak])
+	}
+
+	P, Z := uint32(peak), uint32(zero)
+	idx := 0
+	for k := reversibleHeaderSamples; k < samples; k++ {
+		v := getSample(RGBchannels, k)
+		switch {
+		case v == P:
+			if idx < len(stream) {
+				if stream[idx] == 1 {
+					if Z > P {
+						v++
+					} else {
+						v--
+					}
+				}
+				idx++
+			}
+		case Z > P && v > P && v < Z:
+			v++
+		case Z < P && v < P && v > Z:
+			v--
+		}
+		setSample(RGBchannels, k, v)
+	}
+
+	header := append(BytesToBinary([]byte{byte(peak), byte(zero)}), Int32ToBinary(int32(hist[zero]))...)
+	for k, bit := range header {
+		setSample(RGBchannels, k, getSample(RGBchannels, k)&^1|uint32(bit))
+	}
+
+	return RGBchannels, nil
+}
+
+// ExtractReversible returns the data embedded by EmbedReversible together with the restored original channels.
+// The given channels are left untouched.
+func ExtractReversible(RGBchannels []RgbChannel) ([]byte, []RgbChannel, error) {
+	samples := len(RGBchannels) * 3
+	if samples <= reversibleHeaderSamples {
+		return nil, nil, fmt.Errorf("image is too small")
+	}
+
+	restored := make([]RgbChannel, len(RGBchannels))
+	copy(restored, RGBchannels)
+
+	header := make([]uint8, reversibleHeaderSamples)
+	for k := range header {
+		header[k] = uint8(getSample(restored, k) & 1)
+	}
+
+	P, Z := uint32(bitsToInt(header[:8])), uint32(bitsToInt(header[8:16]))
+	locCount := bitsToInt(header[16:48])
+	if P == Z || locCount < 0 || locCount > samples {
+		return nil, nil, fmt.Errorf("no reversible payload found")
+	}
+
+	var stream []uint8
+	for k := reversibleHeaderSamples; k < samples; k++ {
+		v := getSample(restored, k)
+		switch {
+		case v == P:
+			stream = append(stream, 0)
+		case Z > P && v == P+1, Z < P && v == P-1:
+			stream = append(stream, 1)
+			v = P
+		case Z > P && v > P+1 && v <= Z:
+			v--
+		case Z < P && v < P-1 && v >= Z:
+			v++
+		}
+		setSample(restored, k, v)
+	}
+
+	offset := reversibleHeaderSamples + 32*locCount
+	if len(stream) < offset+32 {
+		return nil, nil, fmt.Errorf("no reversible payload found")
+	}
+
+	for k := 0; k < reversibleHeaderSamples; k++ {
+		setSample(restored, k, getSample(restored, k)&^1|uint32(stream[k]))
+	}
+
+	for i := 0; i < locCount; i++ {
+		k := bitsToInt(stream[reversibleHeaderSamples+32*i : reversibleHeaderSamples+32*(i+1)])
+		if k < reversibleHeaderSamples || k >= samples {
+			return nil, nil, fmt.Errorf("invalid location map entry %d", k)
+		}
+		setSample(restored, k, Z)
+	}
+
+	lenData := bitsToInt(stream[offset : offset+32])
+	if lenData <= 0 || len(stream) < offset+32+lenData*8 {
+		return nil, nil, fmt.Errorf("invalid data length %d", lenData)
+	}
+
+	data := make([]byte, lenData)
+	for i := range data {
+		data[i] = byte(bitsToInt(stream[offset+32+i*8 : offset+32+(i+1)*8]))
+	}
+
+	return data, restored, nil
+}
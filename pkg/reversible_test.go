@@ -0,0 +1,76 @@
+package pkg
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestReversibleRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		channels []RgbChannel
+	}{
+		{
+			name:     "Textured image",
+			channels: createTexturedChannels(160, 160),
+		},
+		{
+			name: "Full histogram without empty bins",
+			channels: func() []RgbChannel {
+				rng := rand.New(rand.NewSource(5))
+				c := make([]RgbChannel, 64*64)
+				for i := range c {
+					c[i] = RgbChannel{R: uint32(rng.Intn(256)), G: 128, B: uint32(i % 256)}
+				}
+				return c
+			}(),
+		},
+		{
+			name: "Peak at 255",
+			channels: func() []RgbChannel {
+				c := make([]RgbChannel, 32*32)
+				for i := range c {
+					c[i] = RgbChannel{R: 255, G: 255, B: uint32(i % 7)}
+				}
+				return c
+			}(),
+		},
+	}
+
+	data := []byte("patient id 1234, scan 2024-01-01")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := make([]RgbChannel, len(tt.channels))
+			copy(original, tt.channels)
+
+			stego, err := EmbedReversible(tt.channels, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			extracted, restored, err := ExtractReversible(stego)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(extracted, data) {
+				t.Fatalf("expected %q, got %q", data, extracted)
+			}
+
+			if !reflect.DeepEqual(restored, original) {
+				t.Fatal("restored channels do not match the original")
+			}
+		})
+	}
+}
+
+func TestReversibleTooLarge(t *testing.T) {
+	channels := createTexturedChannels(16, 16)
+	capacity := ReversibleCapacity(channels)
+
+	if _, err := EmbedReversible(channels, make([]byte, capacity+1)); err == nil {
+		t.Fatal("expected error for data exceeding the capacity")
+	}
+}
@@ -70,6 +70,13 @@ func EmbedIntoRGBchannelsWithDepth(RGBchannels []RgbChannel, data []byte, depth
 	return RGBchannels, nil
 }
 
+// EmbedIntoRGBchannelsWithDepthMonitored works like EmbedIntoRGBchannelsWithDepth, reporting to m and stopping
+// with the context's error once it is cancelled. Bits that do not fit into the channels are dropped.
+func EmbedIntoRGBchannelsWithDepthMonitored(RGBchannels []RgbChannel, data []byte, depth uint8, m *Monitor) ([]RgbChannel, error) {
+	stream := append(intToArr(len(data)), data...)
+	return EmbedRawIntoRGBchannelsWithDepthMonitored(RGBchannels, stream, depth, m)
+}
+
 // uses a single bit at specified index to embed data instead of last n
 func EmbedAtDepth(RGBchannels []RgbChannel, data []byte, depth uint8) ([]RgbChannel, error) {
 	if depth > 7 {
@@ -154,6 +161,12 @@ func ExtractAtDepth(RGBchannels []RgbChannel, depth uint8) ([]byte, error) {
 }
 
 func ExtractDataFromRGBchannelsWithDepth(RGBchannels []RgbChannel, depth uint8) ([]byte, error) {
+	return ExtractDataFromRGBchannelsWithDepthMonitored(RGBchannels, depth, nil)
+}
+
+// ExtractDataFromRGBchannelsWithDepthMonitored works like ExtractDataFromRGBchannelsWithDepth, reporting to m
+// and stopping with the context's error once it is cancelled.
+func ExtractDataFromRGBchannelsWithDepthMonitored(RGBchannels []RgbChannel, depth uint8, m *Monitor) ([]byte, error) {
 	if depth > 7 {
 		return nil, fmt.Errorf("bit depth exeeds 7")
 	}
@@ -162,7 +175,16 @@ func ExtractDataFromRGBchannelsWithDepth(RGBchannels []RgbChannel, depth uint8)
 	var currentByte uint8 = 0
 	bitCount := 0
 
+	perPixel := 3 * (int(depth) + 1)
+	total := int64(len(RGBchannels) * perPixel)
+	step := max(ProgressInterval/perPixel, 1)
 	for i := 0; i < len(RGBchannels); i++ {
+		if i%step == 0 {
+			if err := m.Step(int64(i*perPixel), total); err != nil {
+				return nil, err
+			}
+		}
+
 		for bd := depth + 1; bd > 0; bd-- {
 			r := GetBit(RGBchannels[i].R, bd-1)
 			currentByte = (currentByte << 1) | (r & 1)
@@ -201,5 +223,67 @@ func ExtractDataFromRGBchannelsWithDepth(RGBchannels []RgbChannel, depth uint8)
 		byteSlice = append(byteSlice, currentByte)
 	}
 
+	if err := m.Step(total, total); err != nil {
+		return nil, err
+	}
+
 	return byteSlice, nil
 }
+
+// EmbedRawIntoRGBchannelsWithDepth writes data into the last n bits of each channel without a length prefix.
+// Bits that do not fit into the channels are dropped, so callers can pass a buffer sized to the full capacity.
+func EmbedRawIntoRGBchannelsWithDepth(RGBchannels []RgbChannel, data []byte, depth uint8) ([]RgbChannel, error) {
+	return EmbedRawIntoRGBchannelsWithDepthMonitored(RGBchannels, data, depth, nil)
+}
+
+// EmbedRawIntoRGBchannelsWithDepthMonitored works like EmbedRawIntoRGBchannelsWithDepth, reporting to m
+// and stopping with the context's error once it is cancelled.
+func EmbedRawIntoRGBchannelsWithDepthMonitored(RGBchannels []RgbChannel, data []byte, depth uint8, m *Monitor) ([]RgbChannel, error) {
+	if depth > 7 {
+		return nil, fmt.Errorf("bit depth exeeds 7")
+	}
+
+	binaryData := BytesToBinary(data)
+	if capacity := len(RGBchannels) * 3 * (int(depth) + 1); len(binaryData) > capacity {
+		binaryData = binaryData[:capacity]
+	}
+
+	z := splitIntoGroupsOfThree(binaryData)
+
+	curbit := depth
+	index := 0
+
+	total := int64(len(binaryData))
+	for i := 0; i < len(z); i++ {
+		if i%(ProgressInterval/3) == 0 {
+			if err := m.Step(int64(i*3), total); err != nil {
+				return nil, err
+			}
+		}
+
+		if z[i].r != GetBit(RGBchannels[index].R, curbit) {
+			RGBchannels[index].R = FlipBit(RGBchannels[index].R, curbit)
+		}
+
+		if z[i].g != GetBit(RGBchannels[index].G, curbit) {
+			RGBchannels[index].G = FlipBit(RGBchannels[index].G, curbit)
+		}
+
+		if z[i].b != GetBit(RGBchannels[index].B, curbit) {
+			RGBchannels[index].B = FlipBit(RGBchannels[index].B, curbit)
+		}
+
+		if curbit != 0 {
+			curbit--
+		} else {
+			curbit = depth
+			index++
+		}
+	}
+
+	if err := m.Step(total, total); err != nil {
+		return nil, err
+	}
+
+	return RGBchannels, nil
+}
@@ -95,6 +95,35 @@ func TestExtractAtDepth(t *testing.T) {
 	}
 }
 
+func TestEmbedRawIntoRGBchannelsWithDepth(t *testing.T) {
+	rgbdata := make([]RgbChannel, 8)
+	for i := range rgbdata {
+		rgbdata[i] = RgbChannel{R: 255, G: 255, B: 255}
+	}
+
+	// 8 pixels at depth 1 hold 48 bits, the trailing byte must be dropped
+	data := []byte("abcdefg")
+	var depth uint8 = 1
+
+	ec, err := EmbedRawIntoRGBchannelsWithDepth(rgbdata, data, depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edata, err := ExtractDataFromRGBchannelsWithDepth(ec, depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(edata, data[:6]) {
+		t.Fatalf("expected %v, got %v", data[:6], edata)
+	}
+
+	if _, err := EmbedRawIntoRGBchannelsWithDepth(rgbdata, data, 8); err == nil {
+		t.Fatal("Didnt retrun error when passing in out of bounds bitDepth")
+	}
+}
+
 func TestSplitIntoGroupsOfThree(t *testing.T) {
 	tests := []struct {
 		name     string
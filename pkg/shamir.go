@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Shamir secret sharing over GF(256), byte by byte. Any threshold shares recover the secret,
+// fewer shares reveal nothing about it.
+
+var ErrDuplicateShare = errors.New("shares must have distinct indices")
+
+// ShamirSplit splits secret into n shares of the same length as the secret, any k of which recover it.
+// Share i is the evaluation of the random polynomials at x = i+1.
+func ShamirSplit(secret []byte, n, k int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("secret cannot be empty")
+	}
+
+	if k < 2 || n < k || n > 255 {
+		return nil, fmt.Errorf("invalid threshold %d of %d shares, need 2 <= k <= n <= 255", k, n)
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret))
+	}
+
+	coeffs := make([]byte, k)
+	for b, s := range secret {
+		coeffs[0] = s
+		if _, err := io.ReadFull(rand.Reader, coeffs[1:]); err != nil {
+			return nil, err
+		}
+
+		for i := range shares {
+			x := byte(i + 1)
+			// Horner's rule, highest coefficient first
+			var y byte
+			for j := k - 1; j >= 0; j-- {
+				y = gfMul(y, x) ^ coeffs[j]
+			}
+			shares[i][b] = y
+		}
+	}
+
+	return shares, nil
+}
+
+// ShamirCombine recovers the secret from shares taken at the x coordinates xs (share index + 1) by
+// Lagrange interpolation at x = 0. Passing fewer shares than the threshold returns garbage.
+func ShamirCombine(xs []byte, shares [][]byte) ([]byte, error) {
+	if len(xs) == 0 || len(xs) != len(shares) {
+		return nil, fmt.Errorf("expected one x coordinate per share")
+	}
+
+	for i := range xs {
+		if xs[i] == 0 {
+			return nil, fmt.Errorf("x coordinate cannot be 0")
+		}
+
+		if len(shares[i]) != len(shares[0]) {
+			return nil, fmt.Errorf("shares have different lengths")
+		}
+
+		for j := 0; j < i; j++ {
+			if xs[i] == xs[j] {
+				return nil, ErrDuplicateShare
+			}
+		}
+	}
+
+	// the Lagrange basis at 0 only depends on the x coordinates
+	basis := make([]byte, len(xs))
+	for i := range xs {
+		var num, den byte = 1, 1
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			num = gfMul(num, xs[j])
+			den = gfMul(den, xs[i]^xs[j])
+		}
+		basis[i] = gfDiv(num, den)
+	}
+
+	secret := make([]byte, len(shares[0]))
+	for b := range secret {
+		for i := range shares {
+			secret[b] ^= gfMul(shares[i][b], basis[i])
+		}
+	}
+
+	return secret, nil
+}
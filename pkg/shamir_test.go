@@ -0,0 +1,60 @@
+package pkg
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestShamirSplitCombine(t *testing.T) {
+	secret := []byte("threshold secret")
+
+	shares, err := ShamirSplit(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		indices []int
+		match   bool
+	}{
+		{name: "First three", indices: []int{0, 1, 2}, match: true},
+		{name: "Last three shuffled", indices: []int{4, 2, 3}, match: true},
+		{name: "All five", indices: []int{0, 1, 2, 3, 4}, match: true},
+		{name: "Below threshold", indices: []int{1, 3}, match: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var xs []byte
+			var subset [][]byte
+			for _, i := range tt.indices {
+				xs = append(xs, byte(i+1))
+				subset = append(subset, shares[i])
+			}
+
+			res, err := ShamirCombine(xs, subset)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if bytes.Equal(res, secret) != tt.match {
+				t.Fatalf("expected match %v, got %q", tt.match, res)
+			}
+		})
+	}
+}
+
+func TestShamirInvalid(t *testing.T) {
+	if _, err := ShamirSplit([]byte("s"), 2, 3); err == nil {
+		t.Fatal("expected error for threshold above share count")
+	}
+
+	if _, err := ShamirSplit([]byte("s"), 3, 1); err == nil {
+		t.Fatal("expected error for threshold below 2")
+	}
+
+	if _, err := ShamirCombine([]byte{1, 1}, [][]byte{{1}, {2}}); err != ErrDuplicateShare {
+		t.Fatalf("expected %v, got %v", ErrDuplicateShare, err)
+	}
+}
@@ -0,0 +1,183 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Syndrome-trellis codes (Filler, Judas, Fridrich) embed a message into a binary cover vector, e.g. the LSBs
+// of image channels or audio samples, while minimising the total cost of the changed positions.
+// The message is the syndrome of the stego vector under a sparse parity check matrix built from a small
+// h x w submatrix, so the extractor only needs the stego bits, the message length and h.
+
+const MaxConstraintHeight = 12
+
+var ErrNoSolution = errors.New("no stego vector with finite cost carries the message")
+
+// stcSubmatrix generates the columns of the h-row submatrix. Each column is an h-bit word with the first and last row set,
+// the remaining rows come from a fixed xorshift sequence so embedder and extractor build the same matrix.
+func stcSubmatrix(h, w int) []uint32 {
+	cols := make([]uint32, w)
+	state := uint32(0x9E3779B9) ^ uint32(h*131+w)
+	for i := range cols {
+		state ^= state << 13
+		state ^= state >> 17
+		state ^= state << 5
+		cols[i] = (state & (1<<h - 1)) | 1 | 1<<(h-1)
+	}
+
+	return cols
+}
+
+// stcWidths spreads n cover positions over m message bits as evenly as possible.
+func stcWidths(n, m int) []int {
+	widths := make([]int, m)
+	for i := range widths {
+		widths[i] = (i+1)*n/m - i*n/m
+	}
+
+	return widths
+}
+
+func validateSTC(n, m, h int) error {
+	if m <= 0 {
+		return fmt.Errorf("message cannot be empty")
+	}
+
+	if m > n {
+		return fmt.Errorf("message is longer than the cover")
+	}
+
+	if h < 1 || h > MaxConstraintHeight {
+		return fmt.Errorf("constraint height must be between 1 and %d", MaxConstraintHeight)
+	}
+
+	return nil
+}
+
+// STCEmbed returns the stego bits closest to cover, in terms of the summed costs of changed positions,
+// whose syndrome equals message. Costs may be +Inf for positions that must not change (wet paper).
+// Larger constraint heights h get closer to the optimal embedding efficiency but take 2^h time and memory per cover bit.
+func STCEmbed(cover []uint8, costs []float64, message []uint8, h int) ([]uint8, error) {
+	n, m := len(cover), len(message)
+	if err := validateSTC(n, m, h); err != nil {
+		return nil, err
+	}
+
+	if len(costs) != n {
+		return nil, fmt.Errorf("expected %d costs, got %d", n, len(costs))
+	}
+
+	widths := stcWidths(n, m)
+	cols := stcSubmatrix(h, (n+m-1)/m)
+	states := 1 << h
+
+	wght := make([]float64, states)
+	newWght := make([]float64, states)
+	for s := range wght {
+		wght[s] = math.Inf(1)
+	}
+	wght[0] = 0
+
+	// path holds one bit per cover position and state: whether the best way into the state set y to 1
+	stride := (states + 7) / 8
+	path := make([]byte, n*stride)
+
+	indx := 0
+	for i := 0; i < m; i++ {
+		mask := uint32(states - 1)
+		if m-i < h {
+			mask = 1<<(m-i) - 1
+		}
+
+		for j := 0; j < widths[i]; j++ {
+			col := int(cols[j] & mask)
+			flip0, flip1 := costs[indx], 0.0
+			if cover[indx] == 0 {
+				flip0, flip1 = 0, costs[indx]
+			}
+
+			for s := 0; s < states; s++ {
+				w0 := wght[s] + flip0
+				w1 := wght[s^col] + flip1
+				if w1 < w0 {
+					newWght[s] = w1
+					path[indx*stride+s/8] |= 1 << (s % 8)
+				} else {
+					newWght[s] = w0
+				}
+			}
+
+			wght, newWght = newWght, wght
+			indx++
+		}
+
+		// keep the states whose lowest row matches the message bit and shift that row out
+		for s := 0; s < states/2; s++ {
+			wght[s] = wght[2*s+int(message[i])]
+		}
+		for s := states / 2; s < states; s++ {
+			wght[s] = math.Inf(1)
+		}
+	}
+
+	state := 0
+	for s := range wght {
+		if wght[s] < wght[state] {
+			state = s
+		}
+	}
+
+	if math.IsInf(wght[state], 1) {
+		return nil, ErrNoSolution
+	}
+
+	stego := make([]uint8, n)
+	indx = n - 1
+	for i := m - 1; i >= 0; i-- {
+		mask := uint32(states - 1)
+		if m-i < h {
+			mask = 1<<(m-i) - 1
+		}
+
+		state = (state<<1 | int(message[i])) & (states - 1)
+		for j := widths[i] - 1; j >= 0; j-- {
+			if path[indx*stride+state/8]&(1<<(state%8)) != 0 {
+				stego[indx] = 1
+				state ^= int(cols[j] & mask)
+			}
+			indx--
+		}
+	}
+
+	return stego, nil
+}
+
+// STCExtract computes the message of length messageLen carried by the stego bits.
+func STCExtract(stego []uint8, messageLen int, h int) ([]uint8, error) {
+	n, m := len(stego), messageLen
+	if err := validateSTC(n, m, h); err != nil {
+		return nil, err
+	}
+
+	widths := stcWidths(n, m)
+	cols := stcSubmatrix(h, (n+m-1)/m)
+
+	message := make([]uint8, m)
+	var state uint32
+	indx := 0
+	for i := 0; i < m; i++ {
+		for j := 0; j < widths[i]; j++ {
+			if stego[indx]&1 == 1 {
+				state ^= cols[j]
+			}
+			indx++
+		}
+
+		message[i] = uint8(state & 1)
+		state >>= 1
+	}
+
+	return message, nil
+}
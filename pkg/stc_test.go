@@ -0,0 +1,141 @@
+package pkg
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func randomBits(rng *rand.Rand, n int) []uint8 {
+	bits := make([]uint8, n)
+	for i := range bits {
+		bits[i] = uint8(rng.Intn(2))
+	}
+	return bits
+}
+
+func TestSTCRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(21))
+
+	tests := []struct {
+		name string
+		n, m int
+		h    int
+	}{
+		{name: "Rate 1/4", n: 4000, m: 1000, h: 7},
+		{name: "Uneven widths", n: 1003, m: 300, h: 5},
+		{name: "Full rate", n: 200, m: 200, h: 3},
+		{name: "Height 1", n: 500, m: 100, h: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cover := randomBits(rng, tt.n)
+			message := randomBits(rng, tt.m)
+			costs := make([]float64, tt.n)
+			for i := range costs {
+				costs[i] = 1
+			}
+
+			stego, err := STCEmbed(cover, costs, message, tt.h)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			extracted, err := STCExtract(stego, tt.m, tt.h)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(extracted, message) {
+				t.Fatal("extracted message does not match")
+			}
+		})
+	}
+}
+
+func TestSTCEfficiency(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	n, m := 8000, 2000
+
+	cover := randomBits(rng, n)
+	message := randomBits(rng, m)
+	costs := make([]float64, n)
+	for i := range costs {
+		costs[i] = 1
+	}
+
+	stego, err := STCEmbed(cover, costs, message, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := 0
+	for i := range cover {
+		if cover[i] != stego[i] {
+			changes++
+		}
+	}
+
+	// plain LSB replacement changes about half of the message bits, the code must do much better
+	if efficiency := float64(m) / float64(changes); efficiency < 3.5 {
+		t.Fatalf("embedding efficiency %.2f bits per change is too low", efficiency)
+	}
+}
+
+func TestSTCWetPaper(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	n, m := 2000, 400
+
+	cover := randomBits(rng, n)
+	message := randomBits(rng, m)
+	costs := make([]float64, n)
+	for i := range costs {
+		costs[i] = 1
+		if i%3 == 0 {
+			costs[i] = math.Inf(1)
+		}
+	}
+
+	stego, err := STCEmbed(cover, costs, message, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < n; i += 3 {
+		if stego[i] != cover[i] {
+			t.Fatalf("position %d with infinite cost was changed", i)
+		}
+	}
+
+	extracted, err := STCExtract(stego, m, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(extracted, message) {
+		t.Fatal("extracted message does not match")
+	}
+}
+
+func TestSTCInvalid(t *testing.T) {
+	if _, err := STCEmbed([]uint8{0, 1}, []float64{1, 1}, []uint8{1, 0, 1}, 3); err == nil {
+		t.Fatal("expected error for message longer than cover")
+	}
+
+	if _, err := STCEmbed([]uint8{0, 1}, []float64{1, 1}, []uint8{1}, MaxConstraintHeight+1); err == nil {
+		t.Fatal("expected error for constraint height out of range")
+	}
+
+	cover := []uint8{0, 1, 1, 0}
+	carried, err := STCExtract(cover, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wet := []float64{math.Inf(1), math.Inf(1), math.Inf(1), math.Inf(1)}
+	if _, err := STCEmbed(cover, wet, []uint8{carried[0] ^ 1, carried[1]}, 2); err != ErrNoSolution {
+		t.Fatalf("expected %v when every position is wet, got %v", ErrNoSolution, err)
+	}
+}
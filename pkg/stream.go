@@ -0,0 +1,108 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrChannelsFull = errors.New("no capacity left in the channels")
+
+// channelCursor walks the last depth+1 bits of the samples in order, most significant bit first,
+// in the same layout as EmbedRawIntoRGBchannelsWithOrder.
+type channelCursor struct {
+	channels []RgbChannel
+	depth    uint8
+	order    []int
+	idx      int
+}
+
+func newChannelCursor(RGBchannels []RgbChannel, depth uint8, order []int) (channelCursor, error) {
+	if depth > 7 {
+		return channelCursor{}, fmt.Errorf("bit depth exeeds 7")
+	}
+
+	return channelCursor{channels: RGBchannels, depth: depth, order: order}, nil
+}
+
+// Remaining returns the number of whole bytes left.
+func (c *channelCursor) Remaining() int {
+	return (len(c.order)*(int(c.depth)+1) - c.idx) / 8
+}
+
+// next returns the sample and bit-plane of the next bit.
+func (c *channelCursor) next() (int, uint8) {
+	per := int(c.depth) + 1
+	k, plane := c.order[c.idx/per], uint8(int(c.depth)-c.idx%per)
+	c.idx++
+	return k, plane
+}
+
+// ChannelWriter is an io.Writer embedding the bytes written to it into RGB channels,
+// so a payload can be streamed in without holding it in memory. The channels are modified in place.
+type ChannelWriter struct {
+	channelCursor
+}
+
+// NewChannelWriter returns a writer filling the last depth+1 bits of the samples in order.
+func NewChannelWriter(RGBchannels []RgbChannel, depth uint8, order []int) (*ChannelWriter, error) {
+	cursor, err := newChannelCursor(RGBchannels, depth, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChannelWriter{cursor}, nil
+}
+
+// Write embeds p, failing with ErrChannelsFull once the samples are used up.
+func (w *ChannelWriter) Write(p []byte) (int, error) {
+	for n, b := range p {
+		if w.Remaining() == 0 {
+			return n, ErrChannelsFull
+		}
+
+		for i := 7; i >= 0; i-- {
+			k, plane := w.next()
+			v := getSample(w.channels, k)
+			if GetBit(v, plane) != b>>i&1 {
+				setSample(w.channels, k, FlipBit(v, plane))
+			}
+		}
+	}
+
+	return len(p), nil
+}
+
+// ChannelReader is an io.Reader returning the bytes embedded by a ChannelWriter with the same depth and order.
+type ChannelReader struct {
+	channelCursor
+}
+
+// NewChannelReader returns a reader over the last depth+1 bits of the samples in order.
+func NewChannelReader(RGBchannels []RgbChannel, depth uint8, order []int) (*ChannelReader, error) {
+	cursor, err := newChannelCursor(RGBchannels, depth, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChannelReader{cursor}, nil
+}
+
+// Read fills p with the next embedded bytes, returning io.EOF once every whole byte has been read.
+func (r *ChannelReader) Read(p []byte) (int, error) {
+	if r.Remaining() == 0 {
+		return 0, io.EOF
+	}
+
+	n := min(len(p), r.Remaining())
+	for j := 0; j < n; j++ {
+		var b byte
+		for i := 0; i < 8; i++ {
+			k, plane := r.next()
+			b = b<<1 | GetBit(getSample(r.channels, k), plane)
+		}
+		p[j] = b
+	}
+
+	return n, nil
+}
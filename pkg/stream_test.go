@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestChannelWriterReader(t *testing.T) {
+	data := []byte("streamed into the channels")
+
+	for _, depth := range []uint8{0, 2, 7} {
+		channels := createTexturedChannels(16, 16)
+		order := AutoOrder(channels)
+
+		// the writer must produce the same layout as EmbedRawIntoRGBchannelsWithOrder
+		want, err := EmbedRawIntoRGBchannelsWithOrder(slices.Clone(channels), data, depth, order)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w, err := NewChannelWriter(channels, depth, order)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// write in uneven pieces
+		for _, part := range [][]byte{data[:3], data[3:11], data[11:]} {
+			if _, err := w.Write(part); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if !slices.Equal(channels, want) {
+			t.Fatalf("depth %d: writer layout differs from EmbedRawIntoRGBchannelsWithOrder", depth)
+		}
+
+		r, err := NewChannelReader(channels, depth, order)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := make([]byte, len(data))
+		if _, err := io.ReadFull(r, got); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(got, data) {
+			t.Fatalf("depth %d: expected %q, got %q", depth, data, got)
+		}
+	}
+}
+
+func TestChannelWriterFull(t *testing.T) {
+	channels := createTexturedChannels(4, 4)
+	order := AutoOrder(channels)[:20]
+
+	w, err := NewChannelWriter(channels, 1, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 20 samples at 2 bits hold 5 bytes
+	n, err := w.Write([]byte("123456"))
+	if n != 5 || !errors.Is(err, ErrChannelsFull) {
+		t.Fatalf("expected 5 bytes and %v, got %d and %v", ErrChannelsFull, n, err)
+	}
+
+	r, err := NewChannelReader(channels, 1, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := io.ReadAll(r)
+	if err != nil || string(got) != "12345" {
+		t.Fatalf("expected %q, got %q (%v)", "12345", got, err)
+	}
+
+	if _, err := NewChannelWriter(channels, 8, order); err == nil {
+		t.Fatal("expected error for bit depth 8")
+	}
+}
@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+)
+
+// tardosCutoff bounds the Tardos biases away from 0 and 1, as in the original construction.
+const tardosCutoff = 0.01
+
+// keyedUniform derives a reproducible number in [0, 1) from the key, a label and an index.
+func keyedUniform(key []byte, label string, index int) float64 {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(label))
+	mac.Write(intToArr(index))
+	sum := mac.Sum(nil)
+
+	return float64(binary.BigEndian.Uint64(sum[:8])>>11) / float64(1<<53)
+}
+
+// TardosBiases derives the secret per-position biases of a Tardos code of length m from key.
+func TardosBiases(key []byte, m int) []float64 {
+	tp := math.Asin(math.Sqrt(tardosCutoff))
+	biases := make([]float64, m)
+	for i := range biases {
+		r := tp + keyedUniform(key, "tardos-bias", i)*(math.Pi/2-2*tp)
+		biases[i] = math.Pow(math.Sin(r), 2)
+	}
+
+	return biases
+}
+
+// TardosCodeword derives the codeword of the recipient with the given id. Codewords are reproducible
+// from the key, so no table of issued codewords has to be stored.
+func TardosCodeword(key []byte, id string, biases []float64) []uint8 {
+	codeword := make([]uint8, len(biases))
+	for i, p := range biases {
+		if keyedUniform(key, "tardos-user:"+id, i) < p {
+			codeword[i] = 1
+		}
+	}
+
+	return codeword
+}
+
+// TardosScore computes the symmetric Tardos accusation score of a codeword against the bits found in a leaked copy.
+// Colluders that contributed to the leak score high, innocent recipients score around zero.
+func TardosScore(codeword []uint8, biases []float64, leaked []uint8) float64 {
+	var score float64
+	for i, p := range biases {
+		if i >= len(codeword) || i >= len(leaked) {
+			break
+		}
+
+		match := math.Sqrt((1 - p) / p)
+		mismatch := math.Sqrt(p / (1 - p))
+		if leaked[i] == 0 {
+			match, mismatch = mismatch, match
+		}
+
+		if codeword[i] == leaked[i] {
+			score += match
+		} else {
+			score -= mismatch
+		}
+	}
+
+	return score
+}
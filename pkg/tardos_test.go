@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTardosReproducible(t *testing.T) {
+	key := []byte("key")
+	biases := TardosBiases(key, 128)
+
+	for _, p := range biases {
+		if p < tardosCutoff || p > 1-tardosCutoff {
+			t.Fatalf("bias %v outside of cutoff", p)
+		}
+	}
+
+	if !reflect.DeepEqual(biases, TardosBiases(key, 128)) {
+		t.Fatal("biases are not reproducible")
+	}
+
+	a := TardosCodeword(key, "alice", biases)
+	if !reflect.DeepEqual(a, TardosCodeword(key, "alice", biases)) {
+		t.Fatal("codeword is not reproducible")
+	}
+
+	if reflect.DeepEqual(a, TardosCodeword(key, "bob", biases)) {
+		t.Fatal("different recipients got the same codeword")
+	}
+}
+
+func TestTardosScoreCollusion(t *testing.T) {
+	key := []byte("key")
+	biases := TardosBiases(key, 512)
+
+	ids := []string{"a", "b", "c", "d", "e", "f"}
+	codewords := make([][]uint8, len(ids))
+	for i, id := range ids {
+		codewords[i] = TardosCodeword(key, id, biases)
+	}
+
+	// b and e collude and take a random choice wherever their codewords differ
+	leaked := make([]uint8, len(biases))
+	for i := range leaked {
+		leaked[i] = codewords[1][i]
+		if codewords[1][i] != codewords[4][i] && i%2 == 0 {
+			leaked[i] = codewords[4][i]
+		}
+	}
+
+	var innocentMax float64
+	for i := range ids {
+		if i == 1 || i == 4 {
+			continue
+		}
+		innocentMax = max(innocentMax, TardosScore(codewords[i], biases, leaked))
+	}
+
+	for _, i := range []int{1, 4} {
+		if score := TardosScore(codewords[i], biases, leaked); score <= innocentMax {
+			t.Fatalf("colluder %s scored %v, innocent maximum %v", ids[i], score, innocentMax)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+)
+
+// watermarkCoefficients are the mid-frequency DCT coefficients (row major index) that carry a watermark bit.
+// Brightness changes only affect the DC coefficient and JPEG quantizes these coefficients mildly.
+var watermarkCoefficients = []int{1*8 + 2, 2*8 + 1}
+
+func luma(c RgbChannel) float64 {
+	return 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+}
+
+func clampChannel(v float64) uint32 {
+	return uint32(math.Max(0, math.Min(255, math.Round(v))))
+}
+
+// qimEmbed quantizes c onto the lattice of the given bit.
+func qimEmbed(c float64, bit uint8, step float64) float64 {
+	d := float64(bit) * step / 2
+	return step*math.Round((c-d)/step) + d
+}
+
+// qimDetect returns the bit whose lattice lies closest to c.
+func qimDetect(c float64, step float64) uint8 {
+	d0 := math.Abs(c - qimEmbed(c, 0, step))
+	d1 := math.Abs(c - qimEmbed(c, 1, step))
+	if d1 < d0 {
+		return 1
+	}
+
+	return 0
+}
+
+// WatermarkBlocks returns how many 8x8 blocks of an image with the given dimensions can carry watermark bits.
+func WatermarkBlocks(width, height int) int {
+	return (width / 8) * (height / 8)
+}
+
+// EmbedRobustWatermark embeds bits into the luminance of every 8x8 block using quantization index modulation
+// of mid-frequency DCT coefficients. Bits are repeated round robin over all blocks, so each bit is voted on
+// by many blocks during detection. strength is the quantization step, larger values survive more distortion.
+func EmbedRobustWatermark(RGBchannels []RgbChannel, width, height int, bits []uint8, strength float64) ([]RgbChannel, error) {
+	if len(bits) == 0 {
+		return nil, fmt.Errorf("watermark cannot be empty")
+	}
+
+	if strength <= 0 {
+		return nil, fmt.Errorf("strength must be greater than zero")
+	}
+
+	if len(RGBchannels) != width*height {
+		return nil, fmt.Errorf("rgbchannels do not match image dimensions")
+	}
+
+	if WatermarkBlocks(width, height) < len(bits) {
+		return nil, fmt.Errorf("image has %d blocks, need at least %d", WatermarkBlocks(width, height), len(bits))
+	}
+
+	k := 0
+	var block, delta [64]float64
+	for by := 0; by+8 <= height; by += 8 {
+		for bx := 0; bx+8 <= width; bx += 8 {
+			for y := 0; y < 8; y++ {
+				for x := 0; x < 8; x++ {
+					block[y*8+x] = luma(RGBchannels[(by+y)*width+bx+x])
+				}
+			}
+
+			DCT8x8(&block)
+			delta = [64]float64{}
+			for _, idx := range watermarkCoefficients {
+				delta[idx] = qimEmbed(block[idx], bits[k%len(bits)], strength) - block[idx]
+			}
+			IDCT8x8(&delta)
+
+			// adding the same offset to all channels shifts the luminance by exactly that offset
+			for y := 0; y < 8; y++ {
+				for x := 0; x < 8; x++ {
+					c := &RGBchannels[(by+y)*width+bx+x]
+					d := delta[y*8+x]
+					c.R = clampChannel(float64(c.R) + d)
+					c.G = clampChannel(float64(c.G) + d)
+					c.B = clampChannel(float64(c.B) + d)
+				}
+			}
+
+			k++
+		}
+	}
+
+	return RGBchannels, nil
+}
+
+// DetectRobustWatermark reads nbits watermark bits by majority vote over all blocks.
+// It also returns the fraction of votes that disagreed with the decision, an estimate of the raw bit error rate.
+func DetectRobustWatermark(RGBchannels []RgbChannel, width, height int, nbits int, strength float64) ([]uint8, float64, error) {
+	if nbits <= 0 {
+		return nil, 0, fmt.Errorf("number of bits must be greater than zero")
+	}
+
+	if strength <= 0 {
+		return nil, 0, fmt.Errorf("strength must be greater than zero")
+	}
+
+	if len(RGBchannels) != width*height {
+		return nil, 0, fmt.Errorf("rgbchannels do not match image dimensions")
+	}
+
+	if WatermarkBlocks(width, height) < nbits {
+		return nil, 0, fmt.Errorf("image has %d blocks, need at least %d", WatermarkBlocks(width, height), nbits)
+	}
+
+	ones := make([]int, nbits)
+	votes := make([]int, nbits)
+
+	k := 0
+	var block [64]float64
+	for by := 0; by+8 <= height; by += 8 {
+		for bx := 0; bx+8 <= width; bx += 8 {
+			for y := 0; y < 8; y++ {
+				for x := 0; x < 8; x++ {
+					block[y*8+x] = luma(RGBchannels[(by+y)*width+bx+x])
+				}
+			}
+
+			DCT8x8(&block)
+			for _, idx := range watermarkCoefficients {
+				ones[k%nbits] += int(qimDetect(block[idx], strength))
+				votes[k%nbits]++
+			}
+
+			k++
+		}
+	}
+
+	bits := make([]uint8, nbits)
+	disagreements, total := 0, 0
+	for i := range bits {
+		if ones[i]*2 > votes[i] {
+			bits[i] = 1
+		}
+
+		disagreements += min(ones[i], votes[i]-ones[i])
+		total += votes[i]
+	}
+
+	return bits, float64(disagreements) / float64(total), nil
+}
+
+// ResizeRGBchannels resamples the channels of a width x height image to newWidth x newHeight using bilinear interpolation.
+func ResizeRGBchannels(RGBchannels []RgbChannel, width, height, newWidth, newHeight int) ([]RgbChannel, error) {
+	if width <= 0 || height <= 0 || newWidth <= 0 || newHeight <= 0 {
+		return nil, fmt.Errorf("inavalid image dimensions")
+	}
+
+	if len(RGBchannels) != width*height {
+		return nil, fmt.Errorf("rgbchannels do not match image dimensions")
+	}
+
+	out := make([]RgbChannel, newWidth*newHeight)
+	sx := float64(width) / float64(newWidth)
+	sy := float64(height) / float64(newHeight)
+
+	for y := 0; y < newHeight; y++ {
+		fy := math.Max(0, (float64(y)+0.5)*sy-0.5)
+		y0 := min(int(fy), height-1)
+		y1 := min(y0+1, height-1)
+		wy := fy - float64(y0)
+
+		for x := 0; x < newWidth; x++ {
+			fx := math.Max(0, (float64(x)+0.5)*sx-0.5)
+			x0 := min(int(fx), width-1)
+			x1 := min(x0+1, width-1)
+			wx := fx - float64(x0)
+
+			p00, p01 := RGBchannels[y0*width+x0], RGBchannels[y0*width+x1]
+			p10, p11 := RGBchannels[y1*width+x0], RGBchannels[y1*width+x1]
+
+			lerp := func(a, b, c, d uint32) uint32 {
+				top := float64(a)*(1-wx) + float64(b)*wx
+				bottom := float64(c)*(1-wx) + float64(d)*wx
+				return clampChannel(top*(1-wy) + bottom*wy)
+			}
+
+			out[y*newWidth+x] = RgbChannel{
+				R: lerp(p00.R, p01.R, p10.R, p11.R),
+				G: lerp(p00.G, p01.G, p10.G, p11.G),
+				B: lerp(p00.B, p01.B, p10.B, p11.B),
+			}
+		}
+	}
+
+	return out, nil
+}
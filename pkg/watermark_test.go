@@ -0,0 +1,133 @@
+package pkg
+
+import (
+	"bytes"
+	"image/jpeg"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func createTexturedChannels(width, height int) []RgbChannel {
+	rng := rand.New(rand.NewSource(7))
+	channels := make([]RgbChannel, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := 128 + 60*math.Sin(float64(x)/9)*math.Cos(float64(y)/13) + rng.Float64()*20
+			channels[y*width+x] = RgbChannel{R: clampChannel(v), G: clampChannel(v * 0.8), B: clampChannel(255 - v)}
+		}
+	}
+
+	return channels
+}
+
+func TestDCT8x8RoundTrip(t *testing.T) {
+	var block, orig [64]float64
+	for i := range block {
+		block[i] = float64(i * 3 % 255)
+	}
+	orig = block
+
+	DCT8x8(&block)
+	IDCT8x8(&block)
+
+	for i := range block {
+		if math.Abs(block[i]-orig[i]) > 1e-9 {
+			t.Fatalf("index %d: expected %v, got %v", i, orig[i], block[i])
+		}
+	}
+}
+
+func watermarkBits(n int) []uint8 {
+	rng := rand.New(rand.NewSource(3))
+	bits := make([]uint8, n)
+	for i := range bits {
+		bits[i] = uint8(rng.Intn(2))
+	}
+
+	return bits
+}
+
+func TestRobustWatermarkJPEG(t *testing.T) {
+	width, height := 256, 256
+	bits := watermarkBits(64)
+
+	channels, err := EmbedRobustWatermark(createTexturedChannels(width, height), width, height, bits, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := SaveImage(channels, height, width)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 70}); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := jpeg.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// brighten the recompressed image as well
+	attacked := ExtractRGBChannelsFromImageWithConCurrency(decoded, 1)
+	for i := range attacked {
+		attacked[i].R = clampChannel(float64(attacked[i].R) + 10)
+		attacked[i].G = clampChannel(float64(attacked[i].G) + 10)
+		attacked[i].B = clampChannel(float64(attacked[i].B) + 10)
+	}
+
+	got, ber, err := DetectRobustWatermark(attacked, width, height, len(bits), 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, bits) {
+		t.Fatalf("decoded bits do not match, estimated bit error rate %.3f", ber)
+	}
+}
+
+func TestRobustWatermarkScaling(t *testing.T) {
+	width, height := 256, 256
+	bits := watermarkBits(32)
+
+	channels, err := EmbedRobustWatermark(createTexturedChannels(width, height), width, height, bits, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scaled, err := ResizeRGBchannels(channels, width, height, 230, 230)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := ResizeRGBchannels(scaled, 230, 230, width, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ber, err := DetectRobustWatermark(restored, width, height, len(bits), 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, bits) {
+		t.Fatalf("decoded bits do not match, estimated bit error rate %.3f", ber)
+	}
+}
+
+func TestRobustWatermarkInvalid(t *testing.T) {
+	channels := createTexturedChannels(16, 16)
+	if _, err := EmbedRobustWatermark(channels, 16, 16, watermarkBits(5), 24); err == nil {
+		t.Fatal("expected error for more bits than blocks")
+	}
+
+	if _, _, err := DetectRobustWatermark(channels, 16, 16, 4, 0); err == nil {
+		t.Fatal("expected error for zero strength")
+	}
+
+}
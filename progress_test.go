@@ -0,0 +1,214 @@
+package stegano
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncodeContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	output := filepath.Join(t.TempDir(), "out.png")
+	embedder := &EmbedHandler{1}
+	if err := embedder.EncodeContext(ctx, createTestImage(), []byte("data"), 1, output, false); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error: %v, got: %v", context.Canceled, err)
+	}
+
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Fatal("expected no output file after cancellation")
+	}
+
+	secure := &SecureEmbedHandler{1}
+	if err := secure.EncodeContext(ctx, createTestImage(), []byte("data"), 1, output, "password"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error: %v, got: %v", context.Canceled, err)
+	}
+}
+
+func TestEncodeDecode_Progress(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "out.png")
+	data := bytes.Repeat([]byte{0x42}, 1000)
+
+	var calls int
+	var last, total int64
+	progress := WithProgress(func(done, t int64) {
+		calls++
+		last, total = done, t
+	})
+
+	embedder := &EmbedHandler{1}
+	if err := embedder.Encode(createTestImage(), data, 2, output, false, progress); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls == 0 || last != total {
+		t.Fatalf("expected a final progress call with done == total, got %d calls, %d/%d", calls, last, total)
+	}
+
+	img, err := Decodeimage(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	extractor := &ExtractHandler{1}
+	if _, err := extractor.DecodeContext(ctx, img, 2); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error: %v, got: %v", context.Canceled, err)
+	}
+
+	calls = 0
+	got, err := extractor.DecodeContext(context.Background(), img, 2, progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, data) {
+		t.Fatal("decoded data does not match original")
+	}
+
+	if calls == 0 || last != total {
+		t.Fatalf("expected a final progress call with done == total, got %d calls, %d/%d", calls, last, total)
+	}
+}
+
+func TestWAVContext(t *testing.T) {
+	dir := t.TempDir()
+	cover := filepath.Join(dir, "cover.wav")
+	output := filepath.Join(dir, "out.wav")
+	writeTestWAV(t, cover, createTestAudioCarrier(20000).Buffer.Data)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	embedder := &AudioEmbedHandler{}
+	if err := embedder.EmbedIntoWAVWithDepthContext(ctx, cover, output, []byte("hidden in audio"), 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error: %v, got: %v", context.Canceled, err)
+	}
+
+	var done, total int64
+	progress := WithProgress(func(d, t int64) { done, total = d, t })
+	if err := embedder.EmbedIntoWAVWithDepth(cover, output, []byte("hidden in audio"), 1, progress); err != nil {
+		t.Fatal(err)
+	}
+
+	if total == 0 || done != total {
+		t.Fatalf("expected a final progress call with done == total, got %d/%d", done, total)
+	}
+
+	extractor := &AudioExtractHandler{}
+	if _, err := extractor.ExtractFromWAVWithDepthContext(ctx, output, 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error: %v, got: %v", context.Canceled, err)
+	}
+
+	got, err := extractor.ExtractFromWAVWithDepth(output, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != "hidden in audio" {
+		t.Fatalf("expected %q, got %q", "hidden in audio", got)
+	}
+}
+
+// cancelHalfway returns a context that is cancelled once the progress passed to the option reaches half of the total,
+// and a function reporting the furthest progress seen.
+func cancelHalfway() (context.Context, Option, func() (int64, int64)) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var last, total int64
+	progress := WithProgress(func(done, t int64) {
+		last, total = done, t
+		if done*2 >= t {
+			cancel()
+		}
+	})
+
+	return ctx, progress, func() (int64, int64) { return last, total }
+}
+
+func TestContext_CancelPartway(t *testing.T) {
+	cover := createGradientImage(600, 600)
+	data := make([]byte, 100000)
+	rand.Read(data)
+
+	embeds := []struct {
+		name  string
+		embed func(ctx context.Context, output string, progress Option) error
+	}{
+		{name: "Encode", embed: func(ctx context.Context, output string, progress Option) error {
+			return NewEmbedHandler().EncodeContext(ctx, cover, data, MaxBitDepth, output, false, progress)
+		}},
+		{name: "EncodeAuto", embed: func(ctx context.Context, output string, progress Option) error {
+			return NewEmbedHandler().EncodeAutoContext(ctx, cover, data, output, false, progress)
+		}},
+		{name: "EmbedStream", embed: func(ctx context.Context, output string, progress Option) error {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			return NewEmbedHandler().EmbedStreamContext(ctx, cover, bytes.NewReader(data), int64(len(data)), f, progress)
+		}},
+	}
+
+	for _, tt := range embeds {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, progress, seen := cancelHalfway()
+			output := filepath.Join(t.TempDir(), "out.png")
+
+			if err := tt.embed(ctx, output, progress); !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected error: %v, got: %v", context.Canceled, err)
+			}
+
+			if done, total := seen(); done == 0 || done >= total {
+				t.Fatalf("expected embedding to stop partway, last progress %d/%d", done, total)
+			}
+		})
+	}
+
+	output := filepath.Join(t.TempDir(), "out.png")
+	if err := NewEmbedHandler().EncodeAuto(cover, data, output, false); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := Decodeimage(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extracts := []struct {
+		name    string
+		extract func(ctx context.Context, progress Option) error
+	}{
+		{name: "DecodeAuto", extract: func(ctx context.Context, progress Option) error {
+			_, err := NewExtractHandler().DecodeAutoContext(ctx, img, progress)
+			return err
+		}},
+		{name: "ExtractStream", extract: func(ctx context.Context, progress Option) error {
+			_, err := NewExtractHandler().ExtractStreamContext(ctx, img, io.Discard, progress)
+			return err
+		}},
+	}
+
+	for _, tt := range extracts {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, progress, seen := cancelHalfway()
+			if err := tt.extract(ctx, progress); !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected error: %v, got: %v", context.Canceled, err)
+			}
+
+			if done, total := seen(); done == 0 || done >= total {
+				t.Fatalf("expected extraction to stop partway, last progress %d/%d", done, total)
+			}
+		})
+	}
+}
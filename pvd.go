@@ -0,0 +1,81 @@
+package stegano
+
+import (
+	"image"
+	"runtime"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// GetPVDCapacity returns how many bytes can be embedded into the image with EmbedPVD.
+// Unlike GetImageCapacity this depends on the content, textured images hold more than smooth ones.
+func GetPVDCapacity(coverImage image.Image) int {
+	if coverImage == nil {
+		return 0
+	}
+
+	return u.PVDCapacity(u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, runtime.NumCPU()))
+}
+
+// EmbedPVD embeds data with pixel value differencing. Pairs of adjacent pixels carry more bits where they differ
+// strongly (edges and texture) and fewer where they are similar (smooth areas), instead of using a fixed bit depth.
+//
+// Parameters:
+// - coverImage: The image to embed data into.
+// - data: The data to embed.
+func (m *EmbedHandler) EmbedPVD(coverImage image.Image, data []byte) (image.Image, error) {
+	if coverImage == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if len(data) == 0 {
+		return nil, ErrInvalidData
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	if len(data) > u.PVDCapacity(RGBchannels) {
+		return nil, ErrDataTooLarge
+	}
+
+	embeddedRGBChannels, err := u.EmbedPVD(RGBchannels, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.SaveImage(embeddedRGBChannels, height, width)
+}
+
+// ExtractPVD extracts data embedded with EmbedPVD.
+//
+// Parameters:
+// - coverImage: The image containing the embedded data.
+func (m *ExtractHandler) ExtractPVD(coverImage image.Image) ([]byte, error) {
+	if coverImage == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	return u.ExtractPVD(RGBchannels)
+}
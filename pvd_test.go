@@ -0,0 +1,34 @@
+package stegano
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPVD_RoundTrip(t *testing.T) {
+	cover := createGradientImage(64, 64)
+	data := []byte("pixel value differencing")
+
+	stego, err := NewEmbedHandler().EmbedPVD(cover, data)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	extracted, err := NewExtractHandler().ExtractPVD(stego)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if !bytes.Equal(extracted, data) {
+		t.Fatalf("expected %q, got %q", data, extracted)
+	}
+}
+
+func TestPVD_TooLarge(t *testing.T) {
+	cover := createTestImage()
+
+	_, err := NewEmbedHandler().EmbedPVD(cover, make([]byte, GetPVDCapacity(cover)+1))
+	if err != ErrDataTooLarge {
+		t.Fatalf("expected error: %v, got: %v", ErrDataTooLarge, err)
+	}
+}
@@ -0,0 +1,98 @@
+package stegano
+
+import (
+	"image"
+	"runtime"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// GetReversibleCapacity returns how many bytes can be embedded into the image with EmbedReversible.
+// Unlike GetImageCapacity this depends on the content of the image, as it is bound by the height of the histogram peak.
+func GetReversibleCapacity(coverImage image.Image) int {
+	if coverImage == nil {
+		return 0
+	}
+
+	return u.ReversibleCapacity(u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, runtime.NumCPU()))
+}
+
+// EmbedReversible embeds data with histogram shifting so that ExtractReversible can restore the bit-exact original image.
+// This is meant for images such as medical or legal evidence where the cover itself must remain intact.
+// The image is treated as 8-bit opaque RGB, and the result must be saved losslessly (e.g. with SaveImage).
+//
+// Parameters:
+// - coverImage: The image to embed data into.
+// - data: The data to embed.
+func (m *EmbedHandler) EmbedReversible(coverImage image.Image, data []byte) (image.Image, error) {
+	if coverImage == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if len(data) == 0 {
+		return nil, ErrInvalidData
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	if len(data) > u.ReversibleCapacity(RGBchannels) {
+		return nil, ErrDataTooLarge
+	}
+
+	embeddedRGBChannels, err := u.EmbedReversible(RGBchannels, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.SaveImage(embeddedRGBChannels, height, width)
+}
+
+// ExtractReversible extracts data embedded with EmbedReversible and returns it together with the restored original image.
+//
+// Parameters:
+// - img: The image containing the reversibly embedded data.
+func (m *ExtractHandler) ExtractReversible(img image.Image) ([]byte, image.Image, error) {
+	if img == nil {
+		return nil, nil, ErrInvalidCoverImage
+	}
+
+	height := img.Bounds().Dy()
+	width := img.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return nil, nil, ErrInvalidCoverImage
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(img, m.concurrency)
+	if RGBchannels == nil {
+		return nil, nil, ErrFailedToExtractRGB
+	}
+
+	data, restored, err := u.ExtractReversible(RGBchannels)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	original, err := u.SaveImage(restored, height, width)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, original, nil
+}
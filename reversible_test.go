@@ -0,0 +1,39 @@
+package stegano
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestReversible_RestoresOriginal(t *testing.T) {
+	cover := createGradientImage(128, 128)
+	data := []byte("case 42, exhibit B")
+
+	stego, err := NewEmbedHandler().EmbedReversible(cover, data)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	extracted, original, err := NewExtractHandler().ExtractReversible(stego)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if !bytes.Equal(extracted, data) {
+		t.Fatalf("expected %q, got %q", data, extracted)
+	}
+
+	if !bytes.Equal(original.(*image.RGBA).Pix, cover.(*image.RGBA).Pix) {
+		t.Fatal("restored image does not match the cover")
+	}
+}
+
+func TestReversible_TooLarge(t *testing.T) {
+	cover := createGradientImage(64, 64)
+
+	_, err := NewEmbedHandler().EmbedReversible(cover, make([]byte, GetReversibleCapacity(cover)+1))
+	if err != ErrDataTooLarge {
+		t.Fatalf("expected error: %v, got: %v", ErrDataTooLarge, err)
+	}
+}
@@ -0,0 +1,228 @@
+package stegano
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// Every share body holds the Shamir share of a random key followed by the payload, and an HMAC-SHA256 tag of
+// the payload under that key. The tag can only be checked once the key is recovered, so a share on its own
+// reveals nothing about the payload. The piece header checksum covers the share body, not the payload.
+const (
+	shareKeySize = 16
+	shareTagSize = 16
+)
+
+// maxShareSubsets bounds how many subsets of the shares ExtractShares tries when some of them are corrupt.
+const maxShareSubsets = 1 << 12
+
+// ShareInfo describes the share held by a carrier.
+type ShareInfo struct {
+	SetID [8]byte
+	// Index is the position of the share in the set, starting at 0.
+	Index int
+	// Total is the number of shares that were created.
+	Total int
+	// Threshold is the number of shares needed to recover the payload.
+	Threshold int
+}
+
+// EmbedShares splits data into one Shamir share per carrier, any threshold of which recover the data
+// while fewer reveal nothing about it. Every share is as large as the data plus a key and an authentication tag
+// and uses the same piece header as EmbedAcrossCarriers, so every carrier must hold the full payload plus the overhead.
+//
+// Parameters:
+// - carriers: The carriers to embed into, one share each (at most 255).
+// - data: The data to share.
+// - threshold: The number of shares needed for recovery, between 2 and len(carriers).
+// - bitDepth: The bit depth used for embedding (valid range: 0-7).
+func EmbedShares(carriers []Carrier, data []byte, threshold int, bitDepth uint8) ([]Carrier, error) {
+	if len(carriers) == 0 {
+		return nil, ErrInvalidCarriers
+	}
+
+	if len(data) == 0 {
+		return nil, ErrInvalidData
+	}
+
+	if threshold < 2 || threshold > len(carriers) || len(carriers) > 255 {
+		return nil, ErrInvalidThreshold
+	}
+
+	if bitDepth > MaxBitDepth {
+		return nil, ErrDepthOutOfRange
+	}
+
+	for i, c := range carriers {
+		if c == nil {
+			return nil, ErrInvalidCarriers
+		}
+
+		if c.Capacity(bitDepth) < u.PieceHeaderSize+shareKeySize+len(data)+shareTagSize {
+			return nil, fmt.Errorf("carrier %d cannot hold a share: %w", i, ErrDataTooLarge)
+		}
+	}
+
+	secret := make([]byte, shareKeySize, shareKeySize+len(data))
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	tag := shareTag(secret, data)
+	secret = append(secret, data...)
+
+	shares, err := u.ShamirSplit(secret, len(carriers), threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	header := u.PieceHeader{
+		Kind:      u.PieceShare,
+		Total:     uint16(len(carriers)),
+		Threshold: uint16(threshold),
+		Length:    uint32(len(data)),
+	}
+	if _, err := rand.Read(header.SetID[:]); err != nil {
+		return nil, err
+	}
+
+	out := make([]Carrier, len(carriers))
+	for i, c := range carriers {
+		body := append(shares[i], tag...)
+		header.Seq = uint16(i)
+		header.Checksum = u.Checksum(body)
+		embedded, err := c.Embed(u.MarshalPiece(header, body), bitDepth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed share %d: %w", i, err)
+		}
+
+		out[i] = embedded
+	}
+
+	return out, nil
+}
+
+// InspectShare reports which share a carrier holds without needing any other share.
+//
+// Parameters:
+// - carrier: The carrier to inspect.
+// - bitDepth: The bit depth used during embedding.
+func InspectShare(carrier Carrier, bitDepth uint8) (*ShareInfo, error) {
+	if carrier == nil {
+		return nil, ErrInvalidCarriers
+	}
+
+	data, err := carrier.Extract(bitDepth)
+	if err != nil {
+		return nil, ErrNoPieces
+	}
+
+	h, _, err := u.ParsePiece(data)
+	if err != nil || h.Kind != u.PieceShare {
+		return nil, ErrNoPieces
+	}
+
+	return &ShareInfo{SetID: h.SetID, Index: int(h.Seq), Total: int(h.Total), Threshold: int(h.Threshold)}, nil
+}
+
+// ExtractShares recovers data embedded with EmbedShares from any threshold of the carriers, given in any order.
+// With too few shares the returned error wraps ErrInsufficientShares and states how many more are needed.
+// Shares are combined in order of their index, if the result fails authentication other subsets of the
+// shares are tried before ErrChecksumMismatch is returned.
+//
+// Parameters:
+// - carriers: The carriers holding the shares.
+// - bitDepth: The bit depth used during embedding.
+func ExtractShares(carriers []Carrier, bitDepth uint8) ([]byte, error) {
+	if bitDepth > MaxBitDepth {
+		return nil, ErrDepthOutOfRange
+	}
+
+	header, pieces, err := readPieces(carriers, bitDepth, u.PieceShare)
+	if err != nil {
+		return nil, err
+	}
+
+	k := int(header.Threshold)
+	if len(pieces) < k {
+		return nil, fmt.Errorf("%w: have %d of %d, %d more needed", ErrInsufficientShares, len(pieces), header.Threshold, k-len(pieces))
+	}
+
+	size := shareKeySize + int(header.Length) + shareTagSize
+	var seqs []uint16
+	for seq, body := range pieces {
+		if len(body) == size {
+			seqs = append(seqs, seq)
+		}
+	}
+	slices.Sort(seqs)
+
+	if len(seqs) < k {
+		return nil, ErrChecksumMismatch
+	}
+
+	// subset holds indexes into seqs, starting with the k lowest and advancing in lexicographic order
+	subset := make([]int, k)
+	for i := range subset {
+		subset[i] = i
+	}
+
+	xs := make([]byte, k)
+	shares := make([][]byte, k)
+	tags := make([][]byte, k)
+	for tries := 0; tries < maxShareSubsets; tries++ {
+		for i, idx := range subset {
+			body := pieces[seqs[idx]]
+			xs[i] = byte(seqs[idx] + 1)
+			shares[i] = body[:size-shareTagSize]
+			tags[i] = body[size-shareTagSize:]
+		}
+
+		secret, err := u.ShamirCombine(xs, shares)
+		if err != nil {
+			return nil, err
+		}
+
+		key, data := secret[:shareKeySize], secret[shareKeySize:]
+		expected := shareTag(key, data)
+		for _, tag := range tags {
+			if hmac.Equal(tag, expected) {
+				return data, nil
+			}
+		}
+
+		if !nextSubset(subset, len(seqs)) {
+			break
+		}
+	}
+
+	return nil, ErrChecksumMismatch
+}
+
+// shareTag authenticates the payload under the key shared along with it.
+func shareTag(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)[:shareTagSize]
+}
+
+// nextSubset advances subset, increasing indexes below n, to the next combination in lexicographic order.
+// It returns false once every combination has been visited.
+func nextSubset(subset []int, n int) bool {
+	k := len(subset)
+	for i := k - 1; i >= 0; i-- {
+		if subset[i] < n-k+i {
+			subset[i]++
+			for j := i + 1; j < k; j++ {
+				subset[j] = subset[j-1] + 1
+			}
+			return true
+		}
+	}
+
+	return false
+}
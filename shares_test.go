@@ -0,0 +1,115 @@
+package stegano
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+func TestEmbedShares(t *testing.T) {
+	carriers := []Carrier{
+		NewImageCarrier(createGradientImage(32, 32)),
+		NewImageCarrier(createGradientImage(32, 32)),
+		createTestAudioCarrier(8000),
+		NewImageCarrier(createGradientImage(48, 48)),
+	}
+	data := []byte("any two of four carriers recover this")
+
+	embedded, err := EmbedShares(carriers, data, 2, LSB)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	info, err := InspectShare(embedded[2], LSB)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if info.Index != 2 || info.Total != 4 || info.Threshold != 2 {
+		t.Fatalf("unexpected share info %+v", info)
+	}
+
+	extracted, err := ExtractShares([]Carrier{embedded[3], embedded[1]}, LSB)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if !bytes.Equal(extracted, data) {
+		t.Fatalf("expected %q, got %q", data, extracted)
+	}
+
+	_, err = ExtractShares([]Carrier{embedded[0]}, LSB)
+	if !errors.Is(err, ErrInsufficientShares) || !strings.Contains(err.Error(), "1 more needed") {
+		t.Fatalf("expected error: %v reporting 1 more needed, got: %v", ErrInsufficientShares, err)
+	}
+}
+
+func TestEmbedShares_Errors(t *testing.T) {
+	carriers := []Carrier{NewImageCarrier(createGradientImage(16, 16)), NewImageCarrier(createGradientImage(16, 16))}
+
+	if _, err := EmbedShares(carriers, []byte("data"), 3, LSB); !errors.Is(err, ErrInvalidThreshold) {
+		t.Fatalf("expected error: %v, got: %v", ErrInvalidThreshold, err)
+	}
+
+	if _, err := EmbedShares(carriers, make([]byte, 100), 2, LSB); !errors.Is(err, ErrDataTooLarge) {
+		t.Fatalf("expected error: %v, got: %v", ErrDataTooLarge, err)
+	}
+
+	if _, err := InspectShare(carriers[0], LSB); !errors.Is(err, ErrNoPieces) {
+		t.Fatalf("expected error: %v, got: %v", ErrNoPieces, err)
+	}
+}
+
+func TestExtractShares_CorruptShare(t *testing.T) {
+	carriers := []Carrier{
+		NewImageCarrier(createGradientImage(32, 32)),
+		NewImageCarrier(createGradientImage(32, 32)),
+		NewImageCarrier(createGradientImage(32, 32)),
+	}
+	data := []byte("abcd")
+
+	embedded, err := EmbedShares(carriers, data, 2, LSB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	piece, err := embedded[0].Extract(LSB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h, body, err := u.ParsePiece(piece)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a share must not carry anything derived from the payload alone
+	if h.Checksum == u.Checksum(data) {
+		t.Fatal("share header holds the checksum of the payload")
+	}
+
+	// forge a share that passes its own checksum but combines into the wrong payload
+	forgedBody := bytes.Clone(body)
+	forgedBody[0] ^= 0xFF
+	h.Checksum = u.Checksum(forgedBody)
+	forged, err := NewImageCarrier(createGradientImage(32, 32)).Embed(u.MarshalPiece(h, forgedBody), LSB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extracted, err := ExtractShares([]Carrier{forged, embedded[2], embedded[1]}, LSB)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if !bytes.Equal(extracted, data) {
+		t.Fatalf("expected %q, got %q", data, extracted)
+	}
+
+	if _, err := ExtractShares([]Carrier{forged, embedded[1]}, LSB); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected error: %v, got: %v", ErrChecksumMismatch, err)
+	}
+}
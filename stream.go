@@ -0,0 +1,306 @@
+package stegano
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"slices"
+
+	c "github.com/scott-mescudi/stegano/compression"
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// streamHeaderSize is the length prefix, the payload header and the codec byte written in front of a streamed payload.
+var streamHeaderSize = 4 + len(payloadHeader) + 1
+
+// EmbedStream embeds size bytes read from payload into the cover image and writes the result to out as PNG.
+// The payload is copied into the image as it is read and never held in memory as a whole.
+// Like EncodeAuto the smallest bit depth that fits is picked and stored in a header, the data is stored
+// uncompressed, so the image can also be read with DecodeAuto.
+// Error correction needs the whole payload up front and is not supported.
+//
+// Parameters:
+// - coverImage: The original image where data will be embedded.
+// - payload: The data to embed, at least size bytes must be readable.
+// - size: The number of bytes to read from payload.
+// - out: Where the PNG encoded stego image is written to.
+// - opts: Optional settings such as WithLeastDistortion, WithNoiseFill, WithProgress or WithStats.
+func (m *EmbedHandler) EmbedStream(coverImage image.Image, payload io.Reader, size int64, out io.Writer, opts ...Option) error {
+	return m.EmbedStreamContext(context.Background(), coverImage, payload, size, out, opts...)
+}
+
+// EmbedStreamContext is like EmbedStream but stops with the context's error once ctx is cancelled.
+func (m *EmbedHandler) EmbedStreamContext(ctx context.Context, coverImage image.Image, payload io.Reader, size int64, out io.Writer, opts ...Option) error {
+	if coverImage == nil {
+		return ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return ErrInvalidCoverImage
+	}
+
+	if payload == nil || size <= 0 {
+		return ErrInvalidData
+	}
+
+	if out == nil {
+		return fmt.Errorf("output writer cannot be nil")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	o := applyOptions(opts)
+	o.ctx = ctx
+	if o.errorCorrection {
+		return ErrStreamErrorCorrection
+	}
+
+	if size >= math.MaxUint32-int64(streamHeaderSize) {
+		return ErrDataTooLarge
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return ErrFailedToExtractRGB
+	}
+
+	samples := int64(len(RGBchannels)*3 - u.AutoHeaderSamples)
+	bitDepth := LSB
+	for samples*(int64(bitDepth)+1)/8 < size+int64(streamHeaderSize) {
+		if bitDepth == MaxBitDepth {
+			return ErrDataTooLarge
+		}
+		bitDepth++
+	}
+
+	embeddedRGBChannels, err := recordStats(RGBchannels, width, o, func() ([]u.RgbChannel, error) {
+		order, err := autoOrder(RGBchannels, width, height, bitDepth, o.leastDistortion, false)
+		if err != nil {
+			return nil, err
+		}
+
+		cw, err := u.NewChannelWriter(RGBchannels, bitDepth, order)
+		if err != nil {
+			return nil, err
+		}
+
+		total := (size + int64(streamHeaderSize)) * 8
+		if o.noiseFill {
+			total = int64(cw.Remaining()) * 8
+		}
+		w := &progressWriter{w: cw, m: o.monitor(), total: total}
+
+		// the length counts the payload header and codec byte, as written by EncodeAuto without compression
+		header := binary.BigEndian.AppendUint32(nil, uint32(size+int64(streamHeaderSize)-4))
+		header = append(append(header, payloadHeader...), c.IDNone)
+		if _, err := w.Write(header); err != nil {
+			return nil, err
+		}
+
+		if n, err := io.CopyN(w, payload, size); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("payload ended after %d of %d bytes: %w", n, size, io.ErrUnexpectedEOF)
+			}
+			return nil, err
+		}
+
+		if o.noiseFill {
+			noise, err := u.NewNoiseReader(o.noiseKey, RGBchannels, bitDepth)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := io.CopyN(w, noise, int64(cw.Remaining())); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := w.m.Step(total, total); err != nil {
+			return nil, err
+		}
+
+		if err := u.EmbedAutoHeader(RGBchannels, bitDepth, o.leastDistortion, false); err != nil {
+			return nil, err
+		}
+
+		return RGBchannels, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to embed data into RGB channels: %w", err)
+	}
+
+	imgdata, err := u.SaveImage(embeddedRGBChannels, height, width)
+	if err != nil {
+		return ErrFailedToSaveImage
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	encoder := png.Encoder{
+		CompressionLevel: png.NoCompression,
+	}
+
+	return encoder.Encode(out, imgdata)
+}
+
+// ExtractStream writes the payload embedded with EmbedStream to w and returns the number of bytes written.
+// Uncompressed payloads are copied straight from the image, images written by EncodeAuto with compression
+// are decompressed first.
+//
+// Parameters:
+// - coverImage: The image containing the embedded data.
+// - w: Where the payload is written to.
+// - opts: Optional settings such as WithProgress.
+func (m *ExtractHandler) ExtractStream(coverImage image.Image, w io.Writer, opts ...Option) (int64, error) {
+	return m.ExtractStreamContext(context.Background(), coverImage, w, opts...)
+}
+
+// ExtractStreamContext is like ExtractStream but stops with the context's error once ctx is cancelled.
+func (m *ExtractHandler) ExtractStreamContext(ctx context.Context, coverImage image.Image, w io.Writer, opts ...Option) (int64, error) {
+	if coverImage == nil {
+		return 0, ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return 0, ErrInvalidCoverImage
+	}
+
+	if w == nil {
+		return 0, fmt.Errorf("output writer cannot be nil")
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return 0, ErrFailedToExtractRGB
+	}
+
+	bitDepth, adaptive, _, err := u.ExtractAutoHeader(RGBchannels)
+	if err != nil {
+		return 0, ErrNoDepthHeader
+	}
+
+	order, err := autoOrder(RGBchannels, width, height, bitDepth, adaptive, false)
+	if err != nil {
+		return 0, err
+	}
+
+	cr, err := u.NewChannelReader(RGBchannels, bitDepth, order)
+	if err != nil {
+		return 0, err
+	}
+
+	var length [4]byte
+	if _, err := io.ReadFull(cr, length[:]); err != nil {
+		return 0, ErrFailedToExtractData
+	}
+
+	n := int64(binary.BigEndian.Uint32(length[:]))
+	if n == 0 {
+		return 0, ErrInvalidDataLength
+	}
+
+	if n > int64(cr.Remaining()) {
+		return 0, fmt.Errorf("embedded length %d exceeds the image capacity: %w", n, ErrFailedToExtractData)
+	}
+
+	o := applyOptions(opts)
+	o.ctx = ctx
+	r := &progressReader{r: cr, m: o.monitor(), total: n * 8}
+
+	prefix := make([]byte, min(n, int64(streamHeaderSize-4)))
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, ctxErr
+		}
+		return 0, ErrFailedToExtractData
+	}
+
+	if bytes.Equal(prefix, append(slices.Clone(payloadHeader), c.IDNone)) {
+		written, err := io.CopyN(w, r, n-int64(len(prefix)))
+		if err != nil {
+			return written, err
+		}
+
+		return written, r.m.Step(r.total, r.total)
+	}
+
+	// compressed and legacy payloads have to be read whole before they can be decoded
+	payload := make([]byte, n)
+	copy(payload, prefix)
+	if _, err := io.ReadFull(r, payload[len(prefix):]); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, ctxErr
+		}
+		return 0, ErrFailedToExtractData
+	}
+
+	if err := r.m.Step(r.total, r.total); err != nil {
+		return 0, err
+	}
+
+	data, err := decodePayload(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := w.Write(data)
+	return int64(written), err
+}
+
+// progressWriter reports the bits written through it to m before every write.
+type progressWriter struct {
+	w     io.Writer
+	m     *u.Monitor
+	done  int64
+	total int64
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	if err := p.m.Step(p.done, p.total); err != nil {
+		return 0, err
+	}
+
+	n, err := p.w.Write(b)
+	p.done += int64(n) * 8
+	return n, err
+}
+
+// progressReader reports the bits read through it to m before every read.
+type progressReader struct {
+	r     io.Reader
+	m     *u.Monitor
+	done  int64
+	total int64
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if err := p.m.Step(p.done, p.total); err != nil {
+		return 0, err
+	}
+
+	n, err := p.r.Read(b)
+	p.done += int64(n) * 8
+	return n, err
+}
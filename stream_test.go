@@ -0,0 +1,112 @@
+package stegano
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEmbedStreamExtractStream(t *testing.T) {
+	cover := createGradientImage(64, 64)
+	random := make([]byte, 3000)
+	rand.New(rand.NewSource(2)).Read(random)
+
+	tests := []struct {
+		name string
+		data []byte
+		opts []Option
+	}{
+		{name: "Small payload", data: []byte("streamed payload")},
+		{name: "Large payload", data: random},
+		{name: "Least distortion", data: random, opts: []Option{WithLeastDistortion()}},
+		{name: "Noise fill", data: random[:500], opts: []Option{WithKeyedNoiseFill([]byte("key"))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := NewEmbedHandler().EmbedStream(cover, bytes.NewReader(tt.data), int64(len(tt.data)), &out, tt.opts...); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			img, err := png.Decode(&out)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var extracted bytes.Buffer
+			n, err := NewExtractHandler().ExtractStream(img, &extracted)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if n != int64(len(tt.data)) || !bytes.Equal(extracted.Bytes(), tt.data) {
+				t.Fatal("extracted data does not match")
+			}
+
+			// the format matches EncodeAuto without compression
+			decoded, err := NewExtractHandler().DecodeAuto(img)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if !bytes.Equal(decoded, tt.data) {
+				t.Fatal("DecodeAuto data does not match")
+			}
+		})
+	}
+}
+
+func TestExtractStream_EncodeAutoCompressed(t *testing.T) {
+	data := []byte(strings.Repeat("compressed by EncodeAuto, ", 40))
+	outputFilename := "test_stream_output.png"
+	defer os.Remove(outputFilename)
+
+	if err := NewEmbedHandler().EncodeAuto(createGradientImage(64, 64), data, outputFilename, true); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := Decodeimage(outputFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var extracted bytes.Buffer
+	if _, err := NewExtractHandler().ExtractStream(img, &extracted); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if !bytes.Equal(extracted.Bytes(), data) {
+		t.Fatal("extracted data does not match")
+	}
+}
+
+func TestEmbedStream_Errors(t *testing.T) {
+	cover := createGradientImage(16, 16)
+	handler := NewEmbedHandler()
+
+	if err := handler.EmbedStream(cover, strings.NewReader("short"), 10, io.Discard); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected error: %v, got: %v", io.ErrUnexpectedEOF, err)
+	}
+
+	if err := handler.EmbedStream(cover, bytes.NewReader(make([]byte, 1000)), 1000, io.Discard); !errors.Is(err, ErrDataTooLarge) {
+		t.Fatalf("expected error: %v, got: %v", ErrDataTooLarge, err)
+	}
+
+	if err := handler.EmbedStream(cover, strings.NewReader("data"), 4, io.Discard, WithErrorCorrection()); !errors.Is(err, ErrStreamErrorCorrection) {
+		t.Fatalf("expected error: %v, got: %v", ErrStreamErrorCorrection, err)
+	}
+
+	if err := handler.EmbedStream(cover, strings.NewReader("data"), 0, io.Discard); !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("expected error: %v, got: %v", ErrInvalidData, err)
+	}
+
+	if _, err := NewExtractHandler().ExtractStream(createTestImage(), io.Discard); !errors.Is(err, ErrNoDepthHeader) {
+		t.Fatalf("expected error: %v, got: %v", ErrNoDepthHeader, err)
+	}
+}
@@ -11,6 +11,15 @@ const (
 	MaxBitDepth uint8 = 7
 )
 
+// DefaultWatermarkStrength is the default quantization step used by robust watermarks
+const DefaultWatermarkStrength float64 = 24
+
+// Default Reed-Solomon geometry used by SecureEmbedHandler
+const (
+	DefaultDataShards   = 4
+	DefaultParityShards = 2
+)
+
 // Errors for image_embedder.go and image_core.go
 var (
 	ErrDepthOutOfRange      = errors.New("bitDepth is out of range (0-7)")
@@ -23,6 +32,69 @@ var (
 	ErrFailedToCompressData = errors.New("failed to compress data")
 	ErrFailedToDecryptData  = errors.New("failed to decrypt data")
 	ErrFailedToSaveImage    = errors.New("failed to save image")
+	ErrPayloadVersion       = errors.New("payload was written by a newer, unsupported format version")
+)
+
+// Errors for deniable.go
+var (
+	ErrInvalidPassword      = errors.New("invalid password")
+	ErrSamePassword         = errors.New("decoy and hidden payloads must use different passwords")
+	ErrNoPayloadForPassword = errors.New("no payload could be decrypted with the given password")
+)
+
+// Errors for fragile.go
+var (
+	ErrInvalidKey       = errors.New("key is empty or invalid")
+	ErrInvalidBlockSize = errors.New("block size must be at least 8")
+)
+
+// Errors for visualize.go
+var (
+	ErrInvalidChannel    = errors.New("channel must be Red, Green or Blue")
+	ErrDimensionMismatch = errors.New("images have different dimensions")
+)
+
+// Errors for metrics.go
+var (
+	ErrInvalidAudio = errors.New("audio file could not be opened or is not a valid WAV file")
+)
+
+// Errors for auto.go
+var (
+	ErrNoDepthHeader = errors.New("image carries no auto depth header")
+)
+
+// Errors for carrier.go and multicarrier.go
+var (
+	ErrInvalidCarriers  = errors.New("carriers are empty or contain nil entries")
+	ErrNoPieces         = errors.New("no carrier holds a piece of a multi-carrier payload")
+	ErrMixedSets        = errors.New("carriers hold pieces of different payloads")
+	ErrMissingPieces    = errors.New("pieces of the payload are missing")
+	ErrChecksumMismatch = errors.New("reassembled payload does not match its checksum")
+)
+
+// Errors for shares.go
+var (
+	ErrInvalidThreshold   = errors.New("threshold must be between 2 and the number of carriers (at most 255)")
+	ErrInsufficientShares = errors.New("not enough shares to recover the payload")
+)
+
+// Errors for archive.go
+var (
+	ErrDuplicateFileName = errors.New("archive already holds a file with this name")
+	ErrFileNotInArchive  = errors.New("file not found in archive")
+	ErrUnsafeFileName    = errors.New("file name is absolute or escapes the output directory")
+	ErrFileExists        = errors.New("file already exists")
+)
+
+// Errors for directory.go
+var (
+	ErrEmptyDirectory = errors.New("directory holds no files to embed")
+)
+
+// Errors for stream.go
+var (
+	ErrStreamErrorCorrection = errors.New("error correction is not supported for streamed payloads")
 )
 
 // Errors for methods.go
@@ -0,0 +1,131 @@
+package stegano
+
+import (
+	"image"
+	"image/color"
+	"runtime"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// Channel selects a color channel of an image.
+type Channel uint8
+
+const (
+	Red Channel = iota
+	Green
+	Blue
+)
+
+func channelValue(px u.RgbChannel, channel Channel) uint32 {
+	switch channel {
+	case Green:
+		return px.G
+	case Blue:
+		return px.B
+	}
+
+	return px.R
+}
+
+// RenderBitPlane renders one bit-plane of one channel as a black and white image, white where the bit is set.
+// Plane 0 is the LSB, so the plane of a bit depth used for embedding shows where data was written.
+//
+// Parameters:
+// - img: The image to render.
+// - channel: The channel to take the bits from (Red, Green or Blue).
+// - plane: The bit-plane to render (valid range: 0-7).
+func RenderBitPlane(img image.Image, channel Channel, plane uint8) (*image.Gray, error) {
+	if img == nil || img.Bounds().Dx() <= 0 || img.Bounds().Dy() <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if channel > Blue {
+		return nil, ErrInvalidChannel
+	}
+
+	if plane > MaxBitDepth {
+		return nil, ErrDepthOutOfRange
+	}
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(img, runtime.NumCPU())
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	out := image.NewGray(image.Rect(0, 0, width, height))
+	for i, px := range RGBchannels {
+		if u.GetBit(channelValue(px, channel), plane) == 1 {
+			out.Pix[i] = 255
+		}
+	}
+
+	return out, nil
+}
+
+// RenderDiffHeatmap renders the per pixel difference between a cover and a stego image. Unchanged pixels are black,
+// changed pixels range from blue for the smallest difference in the image to yellow for the largest,
+// so even single LSB changes stand out.
+//
+// Parameters:
+// - coverImage: The original image.
+// - stegoImage: The image with embedded data, must have the same dimensions as coverImage.
+func RenderDiffHeatmap(coverImage, stegoImage image.Image) (*image.RGBA, error) {
+	if coverImage == nil || stegoImage == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	width, height := coverImage.Bounds().Dx(), coverImage.Bounds().Dy()
+	if width <= 0 || height <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if stegoImage.Bounds().Dx() != width || stegoImage.Bounds().Dy() != height {
+		return nil, ErrDimensionMismatch
+	}
+
+	cover := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, runtime.NumCPU())
+	stego := u.ExtractRGBChannelsFromImageWithConCurrency(stegoImage, runtime.NumCPU())
+	if cover == nil || stego == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	diffs := make([]int, len(cover))
+	maxDiff := 0
+	for i := range cover {
+		diffs[i] = absDiff(cover[i].R, stego[i].R) + absDiff(cover[i].G, stego[i].G) + absDiff(cover[i].B, stego[i].B)
+		maxDiff = max(maxDiff, diffs[i])
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i, d := range diffs {
+		c := color.RGBA{A: 255}
+		if d > 0 {
+			c = heatColor(float64(d) / float64(maxDiff))
+		}
+
+		out.SetRGBA(i%width, i/width, c)
+	}
+
+	return out, nil
+}
+
+func absDiff(a, b uint32) int {
+	if a > b {
+		return int(a - b)
+	}
+
+	return int(b - a)
+}
+
+// heatColor maps t in (0, 1] onto a blue, red, yellow ramp.
+func heatColor(t float64) color.RGBA {
+	if t < 0.5 {
+		s := t * 2
+		return color.RGBA{R: uint8(255 * s), B: uint8(255 * (1 - s)), A: 255}
+	}
+
+	s := (t - 0.5) * 2
+	return color.RGBA{R: 255, G: uint8(255 * s), A: 255}
+}
@@ -0,0 +1,81 @@
+package stegano
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestRenderBitPlane(t *testing.T) {
+	img := createGradientImage(64, 64)
+
+	plane, err := RenderBitPlane(img, Green, 3)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			_, g, _, _ := img.At(x, y).RGBA()
+			want := uint8(0)
+			if (g>>8)&(1<<3) != 0 {
+				want = 255
+			}
+
+			if got := plane.GrayAt(x, y).Y; got != want {
+				t.Fatalf("pixel (%d, %d): expected %d, got %d", x, y, want, got)
+			}
+		}
+	}
+}
+
+func TestRenderBitPlane_Invalid(t *testing.T) {
+	if _, err := RenderBitPlane(createTestImage(), Blue+1, 0); !errors.Is(err, ErrInvalidChannel) {
+		t.Fatalf("expected error: %v, got: %v", ErrInvalidChannel, err)
+	}
+
+	if _, err := RenderBitPlane(createTestImage(), Red, 8); !errors.Is(err, ErrDepthOutOfRange) {
+		t.Fatalf("expected error: %v, got: %v", ErrDepthOutOfRange, err)
+	}
+}
+
+func TestRenderDiffHeatmap(t *testing.T) {
+	cover := createGradientImage(64, 64)
+
+	stego, err := NewEmbedHandler().EmbedDataIntoImage(cover, []byte("diff"), LSB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	heatmap, err := RenderDiffHeatmap(cover, stego)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	black := color.RGBA{A: 255}
+	changed := 0
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			r1, g1, b1, _ := cover.At(x, y).RGBA()
+			r2, g2, b2, _ := stego.At(x, y).RGBA()
+			same := r1 == r2 && g1 == g2 && b1 == b2
+
+			if c := heatmap.RGBAAt(x, y); (c == black) != same {
+				t.Fatalf("pixel (%d, %d): heatmap %v does not match change", x, y, c)
+			}
+
+			if !same {
+				changed++
+			}
+		}
+	}
+
+	if changed == 0 {
+		t.Fatal("expected embedding to change some pixels")
+	}
+
+	if _, err := RenderDiffHeatmap(cover, image.NewRGBA(image.Rect(0, 0, 10, 10))); !errors.Is(err, ErrDimensionMismatch) {
+		t.Fatalf("expected error: %v, got: %v", ErrDimensionMismatch, err)
+	}
+}
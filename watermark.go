@@ -0,0 +1,121 @@
+package stegano
+
+import (
+	"image"
+
+	u "github.com/scott-mescudi/stegano/pkg"
+)
+
+// WatermarkResult holds the outcome of a robust watermark detection.
+type WatermarkResult struct {
+	// Data is the detected payload packed into bytes.
+	Data []byte
+	// Bits is the detected payload, one bit per element.
+	Bits []uint8
+	// BitErrorRate estimates the fraction of block votes that were flipped by distortions.
+	// Values approaching 0.5 mean that no watermark was found.
+	BitErrorRate float64
+}
+
+// GetWatermarkCapacity returns the maximum number of payload bytes a robust watermark can carry in the image.
+// Every bit should be repeated over several blocks to survive distortions, so payloads well below this limit are recommended.
+func GetWatermarkCapacity(coverImage image.Image) int {
+	return u.WatermarkBlocks(coverImage.Bounds().Dx(), coverImage.Bounds().Dy()) / 8
+}
+
+// EmbedRobustWatermark embeds a small payload (tens to hundreds of bits) into the DCT domain of the image luminance
+// using quantization index modulation. Unlike LSB embedding the watermark survives JPEG compression down to
+// quality 70, mild scaling and brightness changes, at the cost of a much lower capacity.
+//
+// Parameters:
+// - coverImage: The image to watermark.
+// - payload: The watermark payload.
+// - strength: The quantization step, DefaultWatermarkStrength is a good trade-off between robustness and visibility.
+func (m *EmbedHandler) EmbedRobustWatermark(coverImage image.Image, payload []byte, strength float64) (image.Image, error) {
+	if coverImage == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	height := coverImage.Bounds().Dy()
+	width := coverImage.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if len(payload) == 0 {
+		return nil, ErrInvalidData
+	}
+
+	if len(payload) > GetWatermarkCapacity(coverImage) {
+		return nil, ErrDataTooLarge
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(coverImage, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	watermarked, err := u.EmbedRobustWatermark(RGBchannels, width, height, u.BytesToBinary(payload), strength)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.SaveImage(watermarked, height, width)
+}
+
+// DetectRobustWatermark detects a watermark embedded with EmbedRobustWatermark.
+// If the image was rescaled, pass WithOriginalSize so it is resampled to the watermarked dimensions before detection.
+//
+// Parameters:
+// - img: The possibly distorted watermarked image.
+// - payloadLen: The length of the embedded payload in bytes.
+// - strength: The strength used on embedding.
+func (m *ExtractHandler) DetectRobustWatermark(img image.Image, payloadLen int, strength float64, opts ...Option) (*WatermarkResult, error) {
+	if img == nil {
+		return nil, ErrInvalidCoverImage
+	}
+
+	height := img.Bounds().Dy()
+	width := img.Bounds().Dx()
+	if height <= 0 || width <= 0 {
+		return nil, ErrInvalidCoverImage
+	}
+
+	if payloadLen <= 0 {
+		return nil, ErrInvalidDataLength
+	}
+
+	if m.concurrency <= 0 {
+		m.concurrency = 1
+	}
+
+	RGBchannels := u.ExtractRGBChannelsFromImageWithConCurrency(img, m.concurrency)
+	if RGBchannels == nil {
+		return nil, ErrFailedToExtractRGB
+	}
+
+	o := applyOptions(opts)
+	if o.originalWidth > 0 && o.originalHeight > 0 && (o.originalWidth != width || o.originalHeight != height) {
+		resized, err := u.ResizeRGBchannels(RGBchannels, width, height, o.originalWidth, o.originalHeight)
+		if err != nil {
+			return nil, err
+		}
+		RGBchannels, width, height = resized, o.originalWidth, o.originalHeight
+	}
+
+	bits, ber, err := u.DetectRobustWatermark(RGBchannels, width, height, payloadLen*8, strength)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, payloadLen)
+	for i, bit := range bits {
+		data[i/8] |= bit << (7 - i%8)
+	}
+
+	return &WatermarkResult{Data: data, Bits: bits, BitErrorRate: ber}, nil
+}
@@ -0,0 +1,54 @@
+package stegano
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func createGradientImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(40 + x%176), G: uint8(60 + y%150), B: uint8(90 + (x+y)%120), A: 255})
+		}
+	}
+	return img
+}
+
+func TestRobustWatermark_JPEG(t *testing.T) {
+	payload := []byte("wm-01234")
+
+	watermarked, err := NewEmbedHandler().EmbedRobustWatermark(createGradientImage(256, 256), payload, DefaultWatermarkStrength)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, watermarked, &jpeg.Options{Quality: 70}); err != nil {
+		t.Fatal(err)
+	}
+
+	compressed, err := jpeg.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewExtractHandler().DetectRobustWatermark(compressed, len(payload), DefaultWatermarkStrength)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if !bytes.Equal(result.Data, payload) {
+		t.Fatalf("expected %q, got %q (bit error rate %.3f)", payload, result.Data, result.BitErrorRate)
+	}
+}
+
+func TestRobustWatermark_TooLarge(t *testing.T) {
+	_, err := NewEmbedHandler().EmbedRobustWatermark(createGradientImage(32, 32), []byte("too large"), DefaultWatermarkStrength)
+	if err != ErrDataTooLarge {
+		t.Fatalf("expected error: %v, got: %v", ErrDataTooLarge, err)
+	}
+}